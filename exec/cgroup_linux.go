@@ -0,0 +1,220 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	containerdCgroups "github.com/containerd/cgroups"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+// inheritedLimitFiles are the limits of the cgroup v2 which the process is started in, they are
+// copied to the dedicated cgroup, so that the process is still bound by them
+var inheritedLimitFiles = []string{
+	"cpuset.cpus", "cpuset.mems", cgroups.CGroupV2CPUQuotaFile,
+	cgroups.CGroupV2MemoryLimitFile, "memory.high", "memory.swap.max", "pids.max",
+}
+
+// DedicatedCgroup is the cgroup created for the process of an experiment, so that a limit of the
+// experiment is enforced by the kernel. It is recorded in the state of the experiment, so that
+// destroy removes exactly it.
+type DedicatedCgroup struct {
+	Path    string
+	Version cgroups.CGroupVersion
+	// origin is the cgroup v2 which the process is started in if the dedicated cgroup is its sibling
+	origin    string
+	stateName string
+	uid       string
+}
+
+// NewDedicatedCgroup creates the cgroup name with the controller for the pid and saves it to the
+// state of stateName and uid. The process is moved into it by Enter after the limits are written.
+func NewDedicatedCgroup(ctx context.Context, cgroupRoot, controller, name string, pid int, stateName, uid string,
+) (*DedicatedCgroup, error) {
+	parent, current, version, err := dedicatedCgroupParent(ctx, cgroupRoot, controller, pid)
+	if err != nil {
+		return nil, fmt.Errorf("find the cgroup of pid %d failed, %v", pid, err)
+	}
+	return newDedicatedCgroup(ctx, parent, current, version, controller, name, stateName, uid)
+}
+
+// dedicatedCgroupParent returns the directory where the dedicated cgroup is created, and the
+// cgroup which the pid is in. On cgroup v2 it is the parent of the cgroup of the pid, because a
+// cgroup with processes can not enable a controller for its children, so the dedicated cgroup is
+// a sibling and inherits the limits of the cgroup of the pid instead. On cgroup v1 it is the
+// cgroup of the pid in the hierarchy of the controller.
+func dedicatedCgroupParent(ctx context.Context, cgroupRoot, controller string, pid int,
+) (string, string, cgroups.CGroupVersion, error) {
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	version := cgroups.DetectCGroupVersion(ctx, cgroupRoot)
+	if version == cgroups.CGroupV2 {
+		cgroupPath, err := cgroups.FindCGroupV2Path(ctx, strconv.Itoa(pid), cgroupRoot)
+		if err != nil {
+			return "", "", version, err
+		}
+		if cgroupPath == "" {
+			return "", "", version, fmt.Errorf("cgroup v2 path of pid %d not found", pid)
+		}
+		if filepath.Clean(cgroupPath) == filepath.Clean(cgroupRoot) {
+			return cgroupPath, cgroupPath, version, nil
+		}
+		return filepath.Dir(cgroupPath), cgroupPath, version, nil
+	}
+	subsystemPath, err := PidPath(pid)(containerdCgroups.Name(controller))
+	if err != nil {
+		return "", "", version, err
+	}
+	cgroupPath := filepath.Join(cgroupRoot, controller, subsystemPath)
+	return cgroupPath, cgroupPath, version, nil
+}
+
+func newDedicatedCgroup(ctx context.Context, parent, current string, version cgroups.CGroupVersion,
+	controller, name, stateName, uid string,
+) (*DedicatedCgroup, error) {
+	c := &DedicatedCgroup{Path: filepath.Join(parent, name), Version: version, stateName: stateName, uid: uid}
+	if version == cgroups.CGroupV2 {
+		if err := enableController(parent, controller); err != nil {
+			return nil, err
+		}
+		if filepath.Clean(current) != filepath.Clean(parent) {
+			c.origin = current
+		}
+	}
+	if err := os.Mkdir(c.Path, 0o755); err != nil && !os.IsExist(err) {
+		return nil, fmt.Errorf("create cgroup %s failed, %v", c.Path, err)
+	}
+	if err := SaveState(stateName, uid, []FileValue{{Path: c.Path}}); err != nil {
+		c.Remove(ctx)
+		return nil, fmt.Errorf("save cgroup %s failed, %v", c.Path, err)
+	}
+	if err := c.inheritLimits(); err != nil {
+		c.Remove(ctx)
+		return nil, err
+	}
+	return c, nil
+}
+
+// enableController enables the controller for the children of the cgroup v2 path
+func enableController(cgroupPath, controller string) error {
+	content, err := os.ReadFile(filepath.Join(cgroupPath, "cgroup.subtree_control"))
+	if err != nil {
+		return fmt.Errorf("read cgroup.subtree_control of %s failed, %v", cgroupPath, err)
+	}
+	for _, enabled := range strings.Fields(string(content)) {
+		if enabled == controller {
+			return nil
+		}
+	}
+	return writeCgroupFile(cgroupPath, "cgroup.subtree_control", "+"+controller)
+}
+
+// inheritLimits copies the limits of the cgroup which the process is started in, a limit of a
+// controller which is not enabled is skipped
+func (c *DedicatedCgroup) inheritLimits() error {
+	for _, file := range inheritedLimitFiles {
+		value, ok := c.InheritedLimit(file)
+		if !ok || value == "" {
+			continue
+		}
+		if err := c.Write(file, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InheritedLimit returns the content of the limit file of the cgroup which the process is started
+// in, false if the dedicated cgroup is not its sibling or the file can not be read
+func (c *DedicatedCgroup) InheritedLimit(file string) (string, bool) {
+	if c.origin == "" {
+		return "", false
+	}
+	content, err := os.ReadFile(filepath.Join(c.origin, file))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
+
+// Write writes the value to the file of the dedicated cgroup
+func (c *DedicatedCgroup) Write(file, value string) error {
+	return writeCgroupFile(c.Path, file, value)
+}
+
+// Enter moves the pid into the dedicated cgroup
+func (c *DedicatedCgroup) Enter(pid int) error {
+	return c.Write("cgroup.procs", strconv.Itoa(pid))
+}
+
+// Remove removes the dedicated cgroup and its state, it is used if the process can not enter it
+func (c *DedicatedCgroup) Remove(ctx context.Context) {
+	if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+		log.Warnf(ctx, "remove cgroup %s failed, %v", c.Path, err)
+	}
+	if StateExists(c.stateName, c.uid) {
+		RemoveDedicatedCgroups(ctx, c.stateName, c.uid, 0)
+	}
+}
+
+// RemoveDedicatedCgroups removes the dedicated cgroups recorded in the states of stateName. If
+// the uid is empty, all of them are removed. The killed process leaves its cgroup asynchronously,
+// so the removal is retried up to wait, a cgroup which still has processes can not be removed.
+func RemoveDedicatedCgroups(ctx context.Context, stateName, uid string, wait time.Duration) {
+	// the experiment which did not create a cgroup has no state
+	if uid != "" && uid != spec.UnknownUid && !StateExists(stateName, uid) {
+		return
+	}
+	err := RestoreStateBy(ctx, stateName, uid, func(value FileValue) error {
+		return removeCgroup(value.Path, wait)
+	})
+	if err != nil {
+		log.Warnf(ctx, "remove the cgroups of %s failed, %v", stateName, err)
+	}
+}
+
+func removeCgroup(cgroupPath string, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		err := os.Remove(cgroupPath)
+		if err == nil || os.IsNotExist(err) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func writeCgroupFile(cgroupPath, file, value string) error {
+	if err := os.WriteFile(filepath.Join(cgroupPath, file), []byte(value), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("write %s to %s of %s failed, %v", value, file, cgroupPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+// newCgroupTree creates a fake cgroup v2 parent with the cgroup of a process in it
+func newCgroupTree(t *testing.T, limits map[string]string) (string, string) {
+	parent := t.TempDir()
+	current := filepath.Join(parent, "pod")
+	if err := os.Mkdir(current, 0o755); err != nil {
+		t.Fatalf("create %s failed, %v", current, err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("cpuset memory"), 0o644); err != nil {
+		t.Fatalf("write cgroup.subtree_control failed, %v", err)
+	}
+	for file, value := range limits {
+		if err := os.WriteFile(filepath.Join(current, file), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatalf("write %s failed, %v", file, err)
+		}
+	}
+	return parent, current
+}
+
+func TestNewDedicatedCgroupInheritsLimits(t *testing.T) {
+	useTempStateDir(t)
+	parent, current := newCgroupTree(t, map[string]string{
+		"cpuset.cpus": "0-1",
+		"cpu.max":     "50000 100000",
+		"memory.max":  "max",
+		"pids.max":    "100",
+	})
+	c, err := newDedicatedCgroup(context.Background(), parent, current, cgroups.CGroupV2, "cpu", "chaos_test_uid", "chaos_test", "uid")
+	if err != nil {
+		t.Fatalf("create the dedicated cgroup failed, %v", err)
+	}
+	if c.Path != filepath.Join(parent, "chaos_test_uid") {
+		t.Errorf("unexpected path of the dedicated cgroup: %s", c.Path)
+	}
+	if !StateExists("chaos_test", "uid") {
+		t.Errorf("the dedicated cgroup is not recorded")
+	}
+	// the controller is enabled by writing +cpu, the fake file is overwritten
+	if content, _ := os.ReadFile(filepath.Join(parent, "cgroup.subtree_control")); string(content) != "+cpu" {
+		t.Errorf("the cpu controller is not enabled: %s", content)
+	}
+	for file, expect := range map[string]string{"cpuset.cpus": "0-1", "cpu.max": "50000 100000", "memory.max": "max", "pids.max": "100"} {
+		content, err := os.ReadFile(filepath.Join(c.Path, file))
+		if err != nil || string(content) != expect {
+			t.Errorf("unexpected inherited %s: %s, expected: %s, %v", file, content, expect, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(c.Path, "memory.high")); !os.IsNotExist(err) {
+		t.Errorf("the limit which the cgroup has not is inherited, %v", err)
+	}
+	if limit, ok := c.InheritedLimit("cpu.max"); !ok || limit != "50000 100000" {
+		t.Errorf("unexpected inherited cpu.max: %s, %t", limit, ok)
+	}
+}
+
+func TestNewDedicatedCgroupOfV1(t *testing.T) {
+	useTempStateDir(t)
+	parent := t.TempDir()
+	c, err := newDedicatedCgroup(context.Background(), parent, parent, cgroups.CGroupV1, "cpu", "chaos_test_uid", "chaos_test", "uid")
+	if err != nil {
+		t.Fatalf("create the dedicated cgroup failed, %v", err)
+	}
+	// the child of v1 is bound by the limits of its parent, nothing is inherited
+	if _, ok := c.InheritedLimit("cpu.max"); ok {
+		t.Errorf("unexpected inherited limit of cgroup v1")
+	}
+	entries, err := os.ReadDir(c.Path)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("unexpected files of the dedicated cgroup: %v, %v", entries, err)
+	}
+}
+
+func TestRemoveDedicatedCgroups(t *testing.T) {
+	useTempStateDir(t)
+	parent := t.TempDir()
+	paths := make(map[string]string)
+	for _, uid := range []string{"first", "second", "busy"} {
+		c, err := newDedicatedCgroup(context.Background(), parent, parent, cgroups.CGroupV1, "cpu", "chaos_test_"+uid, "chaos_test", uid)
+		if err != nil {
+			t.Fatalf("create the dedicated cgroup of %s failed, %v", uid, err)
+		}
+		paths[uid] = c.Path
+	}
+	// a cgroup with processes can not be removed, a fake one with a file neither
+	if err := os.WriteFile(filepath.Join(paths["busy"], "cgroup.procs"), []byte("1"), 0o644); err != nil {
+		t.Fatalf("write cgroup.procs failed, %v", err)
+	}
+
+	RemoveDedicatedCgroups(context.Background(), "chaos_test", "first", 0)
+	if _, err := os.Stat(paths["first"]); !os.IsNotExist(err) || StateExists("chaos_test", "first") {
+		t.Errorf("the cgroup of the uid is not removed, %v", err)
+	}
+	if _, err := os.Stat(paths["second"]); err != nil {
+		t.Errorf("the cgroup of the other uid is removed, %v", err)
+	}
+
+	RemoveDedicatedCgroups(context.Background(), "chaos_test", "", 0)
+	if _, err := os.Stat(paths["second"]); !os.IsNotExist(err) || StateExists("chaos_test", "second") {
+		t.Errorf("the cgroup of all uids is not removed, %v", err)
+	}
+	if !StateExists("chaos_test", "busy") {
+		t.Errorf("the state of the cgroup which can not be removed is removed")
+	}
+}
@@ -20,12 +20,10 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"os"
-	osexec "os/exec"
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/channel"
@@ -53,7 +51,99 @@ func NewCpuCommandModelSpec() spec.ExpModelCommandSpec {
 				&FullLoadActionCommand{
 					spec.BaseExpActionCommandSpec{
 						ActionMatchers: []spec.ExpFlagSpec{},
-						ActionFlags:    []spec.ExpFlagSpec{},
+						ActionFlags: []spec.ExpFlagSpec{
+							&spec.ExpFlag{
+								Name:     "numa-node",
+								Desc:     "select the cpus of the numa nodes, for example 0 or 0-1, it narrows cpu-list if both are set",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "socket",
+								Desc:     "select the cpus of the sockets (physical packages), for example 1, it narrows cpu-list if both are set",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "physical-cores-only",
+								Desc:     "select only the first hyperthread of every physical core, so that no two selected cpus share a core",
+								Required: false,
+								NoArgs:   true,
+							},
+							&spec.ExpFlag{
+								Name:     "smt-siblings-of",
+								Desc:     "select the smt siblings (hyperthreads on the same physical core) of the cpus, for example 2-3, the cpus themselves are excluded",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:   "cgroup-quota",
+								Desc:   "Burn without load control, the burner moves itself into a dedicated child cgroup whose cpu quota is cpu-percent of the cores, so the kernel enforces the load. It can not be used with climb-time or pattern",
+								NoArgs: true,
+							},
+							&spec.ExpFlag{
+								Name:     "pattern",
+								Desc:     "load pattern over time: sine, square, sawtooth, step-schedule or random-walk. The climb-time flag is ignored if it is set",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "period",
+								Desc:     "durations(s) of one cycle of the load pattern, or of every step for step-schedule, default value is 60",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "amplitude",
+								Desc:     "amplitude of the load pattern around cpu-percent (0-100), the load varies between cpu-percent - amplitude and cpu-percent + amplitude",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "min-percent",
+								Desc:     "lower bound of the load pattern (0-100), default value is 0",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "max-percent",
+								Desc:     "upper bound of the load pattern (0-100), default value is cpu-percent",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "steps",
+								Desc:     "cpu percents of the step-schedule pattern, for example 20,80,50",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "controller",
+								Desc:     "load controller of the burner: pid or open-loop. The pid controller corrects the load by the cpu usage, the open-loop one burns a fixed duty cycle of cpu-percent and ignores the usage, default value is pid",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "kp",
+								Desc:     "proportional gain of the pid controller, default value is 0.2",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "ki",
+								Desc:     "integral gain of the pid controller, default value is 0.5",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "kd",
+								Desc:     "derivative gain of the pid controller, default value is 0",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "sched-policy",
+								Desc:     "real-time scheduling policy of the burner threads: fifo or rr. The burner runs open-loop and cpu-percent must be less than 100, so that the host is still recoverable",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "sched-priority",
+								Desc:     "real-time priority of the burner threads (1-99), it must be permitted by RLIMIT_RTPRIO unless the burner has CAP_SYS_NICE, default value is 1",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "workload",
+								Desc:     "instructions run by the burner: spin, integer, floating-point, cache-thrash or branch-misprediction. The cache-thrash one walks a buffer twice the size of the last level cache, default value is spin",
+								Required: false,
+							},
+						},
 						ActionExecutor: &cpuExecutor{},
 						ActionExample: `
 # Create a CPU full load experiment
@@ -69,12 +159,45 @@ blade create cpu load --cpu-list 0,3
 blade create cpu load --cpu-list 1-3
 
 # Specified percentage load
-blade create cpu load --cpu-percent 60`,
+blade create cpu load --cpu-percent 60
+
+# Load oscillates between 20% and 80% along a sine wave with a period of 120 seconds
+blade create cpu load --pattern sine --min-percent 20 --max-percent 80 --period 120
+
+# Load switches between 30% and 90% every 30 seconds
+blade create cpu load --pattern square --cpu-percent 60 --amplitude 30 --period 60
+
+# Load holds 20%, 80% and 50% for 5 minutes each, then starts over
+blade create cpu load --pattern step-schedule --steps 20,80,50 --period 300
+
+# 60% load of two cores enforced by the cpu quota of a dedicated cgroup
+blade create cpu load --cpu-percent 60 --cpu-count 2 --cgroup-quota
+
+# 50% load tuned by the gains of the pid controller
+blade create cpu load --cpu-percent 50 --kp 0.3 --ki 0.2
+
+# A fixed duty cycle of 40% on every burned core, regardless of the other load
+blade create cpu load --cpu-percent 40 --controller open-loop
+
+# 80% load which walks a buffer larger than the last level cache, to evict the caches of the neighbors
+blade create cpu load --cpu-percent 80 --workload cache-thrash
+
+# Starve the normal tasks on the core with index 1 by a SCHED_FIFO burner with priority 50 for 90% of every second
+blade create cpu load --cpu-list 1 --cpu-percent 90 --sched-policy fifo --sched-priority 50
+
+# Burn the hyperthread siblings of the cores with index 2 and 3 which a service is pinned to
+blade create cpu load --smt-siblings-of 2,3
+
+# Burn two physical cores of the numa node 1, without their hyperthread siblings
+blade create cpu load --numa-node 1 --physical-cores-only --cpu-count 2`,
 						ActionPrograms:    []string{BurnCpuBin},
 						ActionCategories:  []string{category.SystemCpu},
 						ActionProcessHang: true,
 					},
 				},
+				NewThrottleActionCommand(),
+				NewOfflineActionCommand(),
+				NewFrequencyActionCommand(),
 			},
 			ExpFlags: []spec.ExpFlagSpec{
 				&spec.ExpFlag{
@@ -153,8 +276,8 @@ func (*FullLoadActionCommand) Matchers() []spec.ExpFlagSpec {
 	return []spec.ExpFlagSpec{}
 }
 
-func (*FullLoadActionCommand) Flags() []spec.ExpFlagSpec {
-	return []spec.ExpFlagSpec{}
+func (f *FullLoadActionCommand) Flags() []spec.ExpFlagSpec {
+	return f.ActionFlags
 }
 
 type cpuExecutor struct {
@@ -201,16 +324,27 @@ func (ce *cpuExecutor) Exec(uid string, ctx context.Context, model *spec.ExpMode
 		cpuPercent = 100
 	}
 
-	cpuListStr := model.ActionFlags["cpu-list"]
+	cpuListStr, resp := resolveCpuList(ctx, model.ActionFlags)
+	if resp != nil {
+		return resp
+	}
 	if cpuListStr != "" {
-		if !ce.channel.IsCommandAvailable(ctx, "taskset") {
-			return spec.ResponseFailWithFlags(spec.CommandTasksetNotFound)
-		}
 		cores, err := util.ParseIntegerListToStringSlice("cpu-list", cpuListStr)
 		if err != nil {
 			log.Errorf(ctx, "`%s`: cpu-list is illegal, %s", cpuListStr, err.Error())
 			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", cpuListStr, err.Error())
 		}
+		// the cpu-count flag picks the first cores selected by the topology
+		if cpuCountStr := model.ActionFlags["cpu-count"]; cpuCountStr != "" && topologySelected(model.ActionFlags) {
+			cpuCount, err = strconv.Atoi(cpuCountStr)
+			if err != nil || cpuCount <= 0 {
+				log.Errorf(ctx, "`%s`: cpu-count is illegal, cpu-count value must be a positive integer", cpuCountStr)
+				return spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-count", cpuCountStr, "it must be a positive integer")
+			}
+			if cpuCount < len(cores) {
+				cores = cores[:cpuCount]
+			}
+		}
 		cpuList = strings.Join(cores, ",")
 	} else {
 		// if cpu-list value is not empty, then the cpu-count flag is invalid
@@ -258,6 +392,36 @@ func (ce *cpuExecutor) Exec(uid string, ctx context.Context, model *spec.ExpMode
 		}
 	}
 
+	pattern, resp := parseLoadPattern(ctx, model.ActionFlags, cpuPercent)
+	if resp != nil {
+		return resp
+	}
+	peakPercent := cpuPercent
+	if pattern != nil {
+		peakPercent = pattern.peak()
+	}
+	rt, resp := parseRealtimeSpec(ctx, model.ActionFlags, peakPercent)
+	if resp != nil {
+		return resp
+	}
+	// the pid controller may burn the whole period, a real-time burner would starve the host
+	defaultController := ControllerPID
+	if rt != nil {
+		defaultController = ControllerOpenLoop
+	}
+	controller, resp := parseControllerSpec(ctx, model.ActionFlags, defaultController)
+	if resp != nil {
+		return resp
+	}
+	if rt != nil && controller.name != ControllerOpenLoop {
+		log.Errorf(ctx, "`%s`: controller is illegal, the real-time burner only runs open-loop", controller.name)
+		return spec.ResponseFailWithFlags(spec.ParameterIllegal, "controller", controller.name, "the real-time burner only runs open-loop")
+	}
+	w, resp := parseWorkload(ctx, model.ActionFlags["workload"])
+	if resp != nil {
+		return resp
+	}
+
 	ctx = context.WithValue(ctx, "cgroup-root", model.ActionFlags["cgroup-root"])
 
 	// Apply quota ratio to adjust the target percent
@@ -270,34 +434,33 @@ func (ce *cpuExecutor) Exec(uid string, ctx context.Context, model *spec.ExpMode
 		log.Infof(ctx, "adjusted cpu percent from %d%% to %d%% based on quota ratio %f",
 			cpuPercent, effectivePercent, quotaRatio)
 	}
+	if pattern != nil {
+		pattern.scale(quotaRatio)
+	}
+
+	if model.ActionFlags["cgroup-quota"] == "true" {
+		if climbTime != 0 || pattern != nil || model.ActionFlags["controller"] != "" || rt != nil {
+			log.Errorf(ctx, "cgroup-quota can not be used with climb-time, pattern, controller or sched-policy")
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "cgroup-quota", "true",
+				"it can not be used with climb-time, pattern, controller or sched-policy")
+		}
+		return burnInCgroup(ctx, uid, model.ActionFlags["cgroup-root"], cpuList, cpuCount, effectivePercent, w)
+	}
 
-	return ce.start(ctx, cpuList, cpuCount, effectivePercent, climbTime, model.ActionFlags["cpu-index"])
+	return ce.start(ctx, cpuList, cpuCount, effectivePercent, climbTime, model.ActionFlags["cpu-index"], pattern, controller, w, rt)
 }
 
 // start burn cpu
-func (ce *cpuExecutor) start(ctx context.Context, cpuList string, cpuCount, cpuPercent, climbTime int, cpuIndexStr string) *spec.Response {
+func (ce *cpuExecutor) start(ctx context.Context, cpuList string, cpuCount, cpuPercent, climbTime int, cpuIndexStr string,
+	pattern *loadPattern, controller *controllerSpec, w workload, rt *realtimeSpec,
+) *spec.Response {
 	ctx = context.WithValue(ctx, "cpuCount", cpuCount)
 	if cpuList != "" {
-		cores, err := util.ParseIntegerListToStringSlice("cpu-list", cpuList)
-		if err != nil {
-			log.Errorf(ctx, "`%s`: cpu-list is illegal, %s", cpuList, err.Error())
-			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", cpuList, err.Error())
+		cores, resp := parseCpuList(ctx, cpuList)
+		if resp != nil {
+			return resp
 		}
-		for _, core := range cores {
-
-			args := fmt.Sprintf(`%s create cpu fullload --cpu-count 1 --cpu-percent %d --climb-time %d --cpu-index %s --uid %s`,
-				os.Args[0], cpuPercent, climbTime, core, ctx.Value(spec.Uid))
-
-			args = fmt.Sprintf("-c %s %s", core, args)
-			argsArray := strings.Split(args, " ")
-			command := osexec.CommandContext(ctx, "taskset", argsArray...)
-			command.SysProcAttr = &syscall.SysProcAttr{}
-
-			if err := command.Start(); err != nil {
-				return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("taskset exec failed, %v", err))
-			}
-		}
-		return spec.ReturnSuccess(ctx.Value(spec.Uid))
+		return burnCores(ctx, cores, cpuPercent, climbTime, pattern, controller, w, rt)
 	}
 
 	runtime.GOMAXPROCS(cpuCount)
@@ -316,24 +479,183 @@ func (ce *cpuExecutor) start(ctx context.Context, cpuList string, cpuCount, cpuP
 		}
 	}
 
-	// make CPU slowly climb to some level, to simulate slow resource competition
-	// which system faults cannot be quickly noticed by monitoring system.
-	slope(ctx, cpuPercent, climbTime, &slopePercent, percpu, cpuIndex)
+	if pattern != nil {
+		// make CPU load follow the waveform, e.g. to exercise autoscaler hysteresis
+		follow(ctx, pattern.waveform(), &slopePercent)
+	} else {
+		// make CPU slowly climb to some level, to simulate slow resource competition
+		// which system faults cannot be quickly noticed by monitoring system.
+		slope(ctx, cpuPercent, climbTime, &slopePercent, percpu, cpuIndex)
+	}
 
-	quota := make(chan int64, cpuCount)
+	// all of the burners share a duty cycle, the usage is sampled once for them
+	duty := &dutyCycle{}
+	bound := make(chan error, cpuCount)
 	for i := 0; i < cpuCount; i++ {
-		go burn(ctx, quota, slopePercent, percpu, cpuIndex)
+		go func() {
+			if err := prepareThread(-1, rt); err != nil {
+				bound <- err
+				return
+			}
+			bound <- nil
+			burn(duty, w)
+		}()
 	}
+	for i := 0; i < cpuCount; i++ {
+		if err := <-bound; err != nil {
+			log.Errorf(ctx, "%v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
+		}
+	}
+	control(ctx, controller.newController(func() float64 {
+		return getUsed(ctx, percpu, cpuIndex)
+	}), &slopePercent, duty)
+	return spec.Success()
+}
 
-	for {
-		q := getQuota(ctx, slopePercent, percpu, cpuIndex)
-		for i := 0; i < cpuCount; i++ {
-			quota <- q
+// parseCpuList parses the cpu-list flag, for example 0-3 or 1,3, to the core indexes
+func parseCpuList(ctx context.Context, cpuList string) ([]int, *spec.Response) {
+	coreList, err := util.ParseIntegerListToStringSlice("cpu-list", cpuList)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: cpu-list is illegal, %s", cpuList, err.Error())
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", cpuList, err.Error())
+	}
+	cores := make([]int, len(coreList))
+	for i, core := range coreList {
+		index, err := strconv.Atoi(core)
+		if err != nil {
+			log.Errorf(ctx, "`%s`: cpu-list is illegal, %s", core, err.Error())
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", core, err.Error())
+		}
+		cores[i] = index
+	}
+	return cores, nil
+}
+
+// burnCores pins a burner thread to every core in-process, so that taskset is not needed,
+// and the load of every core is controlled by the usage of itself.
+func burnCores(ctx context.Context, cores []int, cpuPercent, climbTime int, pattern *loadPattern, controller *controllerSpec,
+	w workload, rt *realtimeSpec,
+) *spec.Response {
+	// the usage of a single core is read from the host, the cgroup usage can not tell the cores apart
+	ctx = context.WithValue(ctx, channel.NSTargetFlagName, nil)
+	runtime.GOMAXPROCS(len(cores))
+
+	// the pattern is shared by the cores, but every core climbs from its own usage
+	slopePercents := make([]*float64, len(cores))
+	if pattern != nil {
+		slopePercent := float64(cpuPercent)
+		follow(ctx, pattern.waveform(), &slopePercent)
+		for i := range cores {
+			slopePercents[i] = &slopePercent
+		}
+	} else {
+		// the cores are sampled at the same time, every sample takes a second
+		var sampled sync.WaitGroup
+		for i, index := range cores {
+			slopePercent := float64(cpuPercent)
+			slopePercents[i] = &slopePercent
+			sampled.Add(1)
+			go func() {
+				defer sampled.Done()
+				slope(ctx, cpuPercent, climbTime, &slopePercent, true, index)
+			}()
+		}
+		sampled.Wait()
+	}
+
+	bound := make(chan error, len(cores))
+	for i, index := range cores {
+		duty := &dutyCycle{}
+		go func(index int) {
+			if err := prepareThread(index, rt); err != nil {
+				bound <- err
+				return
+			}
+			bound <- nil
+			burn(duty, w)
+		}(index)
+		go control(ctx, controller.newController(func() float64 {
+			return getUsed(ctx, true, index)
+		}), slopePercents[i], duty)
+	}
+	for range cores {
+		if err := <-bound; err != nil {
+			log.Errorf(ctx, "%v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
+		}
+	}
+	log.Infof(ctx, "burner threads are bound to cpu %v", cores)
+	select {}
+}
+
+// burnInCgroup moves the burner into a dedicated cgroup limited to cpuPercent of the cores,
+// then spins freely on every core and leaves the load control to the kernel.
+func burnInCgroup(ctx context.Context, uid, cgroupRoot, cpuList string, cpuCount, cpuPercent int, w workload) *spec.Response {
+	var cores []int
+	if cpuList != "" {
+		var resp *spec.Response
+		if cores, resp = parseCpuList(ctx, cpuList); resp != nil {
+			return resp
+		}
+		cpuCount = len(cores)
+	}
+
+	if err := enterBurnCgroup(ctx, cgroupRoot, uid, cpuCount, cpuPercent); err != nil {
+		log.Errorf(ctx, "enter the burner cgroup failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
+	}
+
+	runtime.GOMAXPROCS(cpuCount)
+	bound := make(chan error, cpuCount)
+	for i := 0; i < cpuCount; i++ {
+		go func(i int) {
+			core := -1
+			if cores != nil {
+				core = cores[i]
+			}
+			if err := prepareThread(core, nil); err != nil {
+				bound <- err
+				return
+			}
+			bound <- nil
+			for {
+				w.Run(period)
+			}
+		}(i)
+	}
+	for i := 0; i < cpuCount; i++ {
+		if err := <-bound; err != nil {
+			log.Errorf(ctx, "%v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
 		}
 	}
+	select {}
 }
 
-const period = int64(1000000000)
+// prepareThread locks the burner goroutine to its thread if the thread is bound to the core or
+// scheduled by the real-time policy. The thread is never unlocked, so it exits with the goroutine
+// instead of being reused.
+func prepareThread(core int, rt *realtimeSpec) error {
+	if core < 0 && rt == nil {
+		return nil
+	}
+	runtime.LockOSThread()
+	if core >= 0 {
+		if err := bindToCore(core); err != nil {
+			return fmt.Errorf("bind burner thread to cpu %d failed, %v", core, err)
+		}
+	}
+	if rt != nil {
+		if err := setRealtime(rt); err != nil {
+			return fmt.Errorf("set %s policy with priority %d to burner thread failed, %v", rt.policy, rt.priority, err)
+		}
+	}
+	return nil
+}
+
+// period is the duration of a busy and idle cycle of the burner
+const period = time.Second
 
 func slope(ctx context.Context, cpuPercent int, climbTime int, slopePercent *float64, percpu bool, cpuIndex int) {
 	if climbTime != 0 {
@@ -352,45 +674,15 @@ func slope(ctx context.Context, cpuPercent int, climbTime int, slopePercent *flo
 	}
 }
 
-func getQuota(ctx context.Context, slopePercent float64, percpu bool, cpuIndex int) int64 {
-	used := getUsed(ctx, percpu, cpuIndex)
-	log.Debugf(ctx, "cpu usage: %f , percpu: %v, cpuIndex %d", used, percpu, cpuIndex)
-	dx := (slopePercent - used) / 100
-	busy := int64(dx * float64(period))
-	return busy
-}
-
-func burn(ctx context.Context, quota <-chan int64, slopePercent float64, percpu bool, cpuIndex int) {
-	q := getQuota(ctx, slopePercent, percpu, cpuIndex)
-	ds := period - q
-	if ds < 0 {
-		ds = 0
-	}
-	s, _ := time.ParseDuration(strconv.FormatInt(ds, 10) + "ns")
-	for {
-		startTime := time.Now().UnixNano()
-		select {
-		case offset := <-quota:
-			q = q + offset
-			if q < 0 {
-				q = 0
-			}
-			ds := period - q
-			if ds < 0 {
-				ds = 0
-			}
-			s, _ = time.ParseDuration(strconv.FormatInt(ds, 10) + "ns")
-		default:
-			for time.Now().UnixNano()-startTime < q {
-			}
-			runtime.Gosched()
-			time.Sleep(s)
-		}
-	}
-}
-
 // stop burn cpu
 func (ce *cpuExecutor) stop(ctx context.Context) *spec.Response {
 	ctx = context.WithValue(ctx, "bin", BurnCpuBin)
-	return exec.Destroy(ctx, ce.channel, "cpu fullload")
+	response := exec.Destroy(ctx, ce.channel, "cpu fullload")
+	// remove the dedicated cgroup created by the cgroup-quota mode
+	uid, _ := ctx.Value(spec.Uid).(string)
+	if uid == spec.UnknownUid {
+		uid = ""
+	}
+	removeBurnCgroup(ctx, uid)
+	return response
 }
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	containerdCgroups "github.com/containerd/cgroups"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+const (
+	// burnCgroupPrefix is the name prefix of the dedicated cgroup of the burner, the uid is appended
+	burnCgroupPrefix = "chaos_burncpu_"
+	// burnCgroupPeriod is the cfs period of the dedicated cgroup, in microseconds
+	burnCgroupPeriod = 100000
+	// burnCgroupMinQuota is the minimal cfs quota accepted by the kernel, in microseconds
+	burnCgroupMinQuota = 1000
+)
+
+// enterBurnCgroup creates the dedicated cgroup limited to cpuPercent of cpuCount cores and
+// moves the burner into it, so the load is enforced by the kernel instead of the burn loop.
+func enterBurnCgroup(ctx context.Context, cgroupRoot, uid string, cpuCount, cpuPercent int) error {
+	burnCgroup, err := exec.NewDedicatedCgroup(ctx, cgroupRoot, cgroups.CGroupV2CPUController,
+		burnCgroupPrefix+uid, os.Getpid(), BurnCpuBin, uid)
+	if err != nil {
+		return err
+	}
+	inherited, _ := burnCgroup.InheritedLimit(cgroups.CGroupV2CPUQuotaFile)
+	quota := burnQuota(cpuCount, cpuPercent, inherited)
+	if burnCgroup.Version == cgroups.CGroupV2 {
+		err = burnCgroup.Write(cgroups.CGroupV2CPUQuotaFile, fmt.Sprintf("%d %d", quota, burnCgroupPeriod))
+	} else {
+		err = burnCgroup.Write("cpu.cfs_period_us", strconv.Itoa(burnCgroupPeriod))
+		if err == nil {
+			err = burnCgroup.Write("cpu.cfs_quota_us", strconv.FormatInt(quota, 10))
+		}
+	}
+	if err == nil {
+		err = burnCgroup.Enter(os.Getpid())
+	}
+	if err != nil {
+		burnCgroup.Remove(ctx)
+		return err
+	}
+	log.Infof(ctx, "burner moved into cgroup %s, cpu quota: %d, period: %d", burnCgroup.Path, quota, burnCgroupPeriod)
+	return nil
+}
+
+// burnQuota returns the cfs quota of cpuPercent of cpuCount cores in the period of the burner
+// cgroup, it does not exceed the inherited cpu.max of the cgroup v2 which the burner is started in.
+func burnQuota(cpuCount, cpuPercent int, inherited string) int64 {
+	quota := int64(cpuPercent) * int64(cpuCount) * burnCgroupPeriod / 100
+	if fields := strings.Fields(inherited); len(fields) == 2 && fields[0] != "max" {
+		limit, err := strconv.ParseInt(fields[0], 10, 64)
+		period, periodErr := strconv.ParseInt(fields[1], 10, 64)
+		if err == nil && periodErr == nil && period > 0 {
+			quota = min(quota, limit*burnCgroupPeriod/period)
+		}
+	}
+	return max(quota, burnCgroupMinQuota)
+}
+
+// removeBurnCgroup removes the dedicated cgroups recorded by the killed burners. If the uid is
+// empty, all of them are removed.
+func removeBurnCgroup(ctx context.Context, uid string) {
+	exec.RemoveDedicatedCgroups(ctx, BurnCpuBin, uid, time.Second)
+}
+
+// throttleCgroup lowers the cpu quota of the cgroup of the pid to cpuPercent of the current
+// quota, or of all cores if it is unlimited. The original value is saved to the state of uid.
+func throttleCgroup(ctx context.Context, cgroupRoot, uid string, pid, cpuPercent int) (string, error) {
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	if cgroups.DetectCGroupVersion(ctx, cgroupRoot) == cgroups.CGroupV2 {
+		cgroupPath, err := cgroups.FindCGroupV2Path(ctx, strconv.Itoa(pid), cgroupRoot)
+		if err != nil {
+			return "", err
+		}
+		if cgroupPath == "" {
+			return "", fmt.Errorf("cgroup v2 path of pid %d not found", pid)
+		}
+		quotaFile := filepath.Join(cgroupPath, cgroups.CGroupV2CPUQuotaFile)
+		content, err := os.ReadFile(quotaFile)
+		if err != nil {
+			return "", err
+		}
+		original := strings.TrimSpace(string(content))
+		fields := strings.Fields(original)
+		if len(fields) != 2 {
+			return "", fmt.Errorf("invalid %s format: %s", quotaFile, original)
+		}
+		period, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return "", err
+		}
+		quota := period * int64(runtime.NumCPU())
+		if fields[0] != "max" {
+			if quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+				return "", err
+			}
+		}
+		value := fmt.Sprintf("%d %d", throttledQuota(quota, cpuPercent), period)
+		return value, writeThrottledQuota(ctx, uid, quotaFile, original, value)
+	}
+
+	cpuPath, err := exec.PidPath(pid)(containerdCgroups.Cpu)
+	if err != nil {
+		return "", err
+	}
+	cgroupPath := filepath.Join(cgroupRoot, string(containerdCgroups.Cpu), cpuPath)
+	period, err := readCgroupInt(cgroupPath, "cpu.cfs_period_us")
+	if err != nil {
+		return "", err
+	}
+	original, err := readCgroupInt(cgroupPath, "cpu.cfs_quota_us")
+	if err != nil {
+		return "", err
+	}
+	quota := original
+	if quota <= 0 {
+		quota = period * int64(runtime.NumCPU())
+	}
+	value := strconv.FormatInt(throttledQuota(quota, cpuPercent), 10)
+	return value, writeThrottledQuota(ctx, uid, filepath.Join(cgroupPath, "cpu.cfs_quota_us"), strconv.FormatInt(original, 10), value)
+}
+
+func throttledQuota(quota int64, cpuPercent int) int64 {
+	throttled := quota * int64(cpuPercent) / 100
+	if throttled < burnCgroupMinQuota {
+		throttled = burnCgroupMinQuota
+	}
+	return throttled
+}
+
+// writeThrottledQuota saves the original quota before writing the throttled one, so that it is always recoverable
+func writeThrottledQuota(ctx context.Context, uid, quotaFile, original, value string) error {
+	if err := exec.SaveState(ThrottleCpuBin, uid, []exec.FileValue{{Path: quotaFile, Value: original}}); err != nil {
+		return fmt.Errorf("save the original quota failed, %v", err)
+	}
+	if err := os.WriteFile(quotaFile, []byte(value), 0o644); err != nil { //nolint:gosec
+		if restoreErr := exec.RestoreState(ctx, ThrottleCpuBin, uid); restoreErr != nil {
+			log.Warnf(ctx, "remove the state of %s failed, %v", uid, restoreErr)
+		}
+		return fmt.Errorf("write %s to %s failed, %v", value, quotaFile, err)
+	}
+	log.Infof(ctx, "cpu quota of %s is throttled from %s to %s", quotaFile, original, value)
+	return nil
+}
+
+func readCgroupInt(cgroupPath, file string) (int64, error) {
+	content, err := os.ReadFile(filepath.Join(cgroupPath, file))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import "testing"
+
+func TestBurnQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		cpuCount   int
+		cpuPercent int
+		inherited  string
+		expect     int64
+	}{
+		{"percent of the cores", 2, 60, "", 120000},
+		{"unlimited cgroup", 4, 50, "max 100000", 200000},
+		{"clamped to the cgroup", 4, 50, "150000 100000", 150000},
+		{"clamped in another period", 4, 50, "75000 50000", 150000},
+		{"below the cgroup", 1, 50, "150000 100000", 50000},
+		{"minimal quota", 1, 0, "", burnCgroupMinQuota},
+		{"broken cpu.max", 1, 50, "broken", 50000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if quota := burnQuota(tt.cpuCount, tt.cpuPercent, tt.inherited); quota != tt.expect {
+				t.Errorf("unexpected quota: %d, expected: %d", quota, tt.expect)
+			}
+		})
+	}
+}
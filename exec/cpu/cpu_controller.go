@@ -0,0 +1,170 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+const (
+	ControllerPID      = "pid"
+	ControllerOpenLoop = "open-loop"
+)
+
+const (
+	defaultKp = 0.2
+	defaultKi = 0.5
+	defaultKd = 0.0
+)
+
+// usageSampler returns the cpu usage in percent of what the burner controls, it may block for the sampling interval
+type usageSampler func() float64
+
+// loadController decides the busy ratio of every period of the burner to reach the target percent
+type loadController interface {
+	// Next returns the busy ratio of the next period, between 0 and 1
+	Next(target float64) float64
+}
+
+// controllerSpec holds the parsed controller flags of cpu fullload, every burner gets its own controller
+type controllerSpec struct {
+	name string
+	kp   float64
+	ki   float64
+	kd   float64
+}
+
+// parseControllerSpec parses the controller flags, the controller named defaultName is used if it is not set
+func parseControllerSpec(ctx context.Context, flags map[string]string, defaultName string) (*controllerSpec, *spec.Response) {
+	cs := &controllerSpec{name: defaultName, kp: defaultKp, ki: defaultKi, kd: defaultKd}
+	if name := flags["controller"]; name != "" {
+		if name != ControllerPID && name != ControllerOpenLoop {
+			log.Errorf(ctx, "`%s`: controller is illegal, it must be %s or %s", name, ControllerPID, ControllerOpenLoop)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "controller", name, "it must be pid or open-loop")
+		}
+		cs.name = name
+	}
+	for _, gain := range []struct {
+		name  string
+		value *float64
+	}{{"kp", &cs.kp}, {"ki", &cs.ki}, {"kd", &cs.kd}} {
+		valueStr := flags[gain.name]
+		if valueStr == "" {
+			continue
+		}
+		if cs.name != ControllerPID {
+			log.Errorf(ctx, "`%s`: %s is only used by the pid controller", valueStr, gain.name)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, gain.name, valueStr, "it is only used by the pid controller")
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+			log.Errorf(ctx, "`%s`: %s is illegal, it must be a non-negative number", valueStr, gain.name)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, gain.name, valueStr, "it must be a non-negative number")
+		}
+		*gain.value = value
+	}
+	return cs, nil
+}
+
+func (cs *controllerSpec) newController(sample usageSampler) loadController {
+	if cs.name == ControllerOpenLoop {
+		return &openLoopController{}
+	}
+	return &pidController{kp: cs.kp, ki: cs.ki, kd: cs.kd, sample: sample}
+}
+
+// pidController feeds the target forward and corrects it by the error between the target and
+// the sampled usage, which comes from the other processes or from the cores not burned.
+type pidController struct {
+	kp, ki, kd float64
+	sample     usageSampler
+
+	integral float64
+	prevErr  float64
+	sampled  bool
+}
+
+func (p *pidController) Next(target float64) float64 {
+	e := (target - p.sample()) / 100
+	var derivative float64
+	if p.sampled {
+		derivative = e - p.prevErr
+	}
+	p.prevErr, p.sampled = e, true
+
+	integral := p.integral + e
+	u := target/100 + p.kp*e + p.ki*integral + p.kd*derivative
+	// stop integrating while the output is saturated in the direction of the error, so that it does not wind up
+	if !(u > 1 && e > 0) && !(u < 0 && e < 0) {
+		p.integral = integral
+	}
+	return clampRatio(u)
+}
+
+// openLoopController burns the target percent of every period regardless of the system usage
+type openLoopController struct{}
+
+func (*openLoopController) Next(target float64) float64 {
+	return clampRatio(target / 100)
+}
+
+func clampRatio(ratio float64) float64 {
+	return math.Min(math.Max(ratio, 0), 1)
+}
+
+// dutyCycle is the busy ratio shared by the controller and the burners
+type dutyCycle struct {
+	bits atomic.Uint64
+}
+
+func (d *dutyCycle) Load() float64 {
+	return math.Float64frombits(d.bits.Load())
+}
+
+func (d *dutyCycle) Store(ratio float64) {
+	d.bits.Store(math.Float64bits(ratio))
+}
+
+// control updates the duty cycle by the controller every second, the target follows slopePercent
+func control(ctx context.Context, c loadController, slopePercent *float64, duty *dutyCycle) {
+	duty.Store(clampRatio(*slopePercent / 100))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		ratio := c.Next(*slopePercent)
+		log.Debugf(ctx, "cpu load target: %f, duty cycle: %f", *slopePercent, ratio)
+		duty.Store(ratio)
+	}
+}
+
+// burn runs the workload for the duty cycle of every period and sleeps for the rest of it
+func burn(duty *dutyCycle, w workload) {
+	for {
+		busy := time.Duration(duty.Load() * float64(period))
+		w.Run(busy)
+		runtime.Gosched()
+		time.Sleep(period - busy)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// plant simulates the burned cores, the usage is the background load plus the duty cycle of the last period
+type plant struct {
+	background float64
+	duty       float64
+}
+
+func (p *plant) sample() float64 {
+	return math.Min(p.background+p.duty*100, 100)
+}
+
+func TestPIDControllerConverges(t *testing.T) {
+	tests := []struct {
+		name       string
+		background float64
+		target     float64
+	}{
+		{"idle host", 0, 60},
+		{"busy host", 30, 60},
+		{"target below background", 50, 40},
+		{"high target", 20, 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &plant{background: tt.background}
+			c := (&controllerSpec{name: ControllerPID, kp: defaultKp, ki: defaultKi, kd: defaultKd}).newController(p.sample)
+			for i := 0; i < 30; i++ {
+				p.duty = c.Next(tt.target)
+				if p.duty < 0 || p.duty > 1 {
+					t.Fatalf("duty cycle %f is out of range", p.duty)
+				}
+			}
+			expect := clampRatio((tt.target - tt.background) / 100)
+			if math.Abs(p.duty-expect) > 0.01 {
+				t.Errorf("unexpected duty cycle: %f, expected: %f", p.duty, expect)
+			}
+		})
+	}
+}
+
+func TestPIDControllerAntiWindup(t *testing.T) {
+	p := &plant{background: 80}
+	c := (&controllerSpec{name: ControllerPID, kp: defaultKp, ki: defaultKi}).newController(p.sample)
+	// the target can not be reached while the background load is high
+	for i := 0; i < 100; i++ {
+		p.duty = c.Next(50)
+	}
+	if p.duty != 0 {
+		t.Fatalf("unexpected duty cycle: %f, expected: 0", p.duty)
+	}
+	// the load is followed soon after the background load goes away
+	p.background = 0
+	for i := 0; i < 10; i++ {
+		p.duty = c.Next(50)
+	}
+	if math.Abs(p.duty-0.5) > 0.05 {
+		t.Errorf("unexpected duty cycle: %f, expected: 0.5", p.duty)
+	}
+}
+
+func TestOpenLoopController(t *testing.T) {
+	c := (&controllerSpec{name: ControllerOpenLoop}).newController(func() float64 {
+		t.Fatal("the open-loop controller must not sample the usage")
+		return 0
+	})
+	for target, expect := range map[float64]float64{40: 0.4, 0: 0, 100: 1, 120: 1} {
+		if got := c.Next(target); got != expect {
+			t.Errorf("unexpected duty cycle of %f: %f, expected: %f", target, got, expect)
+		}
+	}
+}
+
+func TestParseControllerSpecIllegal(t *testing.T) {
+	tests := []map[string]string{
+		{"controller": "bang-bang"},
+		{"kp": "-1"},
+		{"ki": "fast"},
+		{"controller": ControllerOpenLoop, "kd": "0.1"},
+	}
+	for _, flags := range tests {
+		if _, resp := parseControllerSpec(context.Background(), flags, ControllerPID); resp == nil {
+			t.Errorf("expected %v to be illegal", flags)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package cpu
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/log"
@@ -37,3 +38,30 @@ func getUsed(ctx context.Context, percpu bool, cpuIndex int) float64 {
 	}
 	return totalCpuPercent[0]
 }
+
+// bindToCore is not supported on darwin, there is no api to set the affinity of a thread
+func bindToCore(core int) error {
+	return errors.New("cpu affinity is not supported on darwin")
+}
+
+// enterBurnCgroup is not supported on darwin, there is no cgroup
+func enterBurnCgroup(ctx context.Context, cgroupRoot, uid string, cpuCount, cpuPercent int) error {
+	return errors.New("cgroup is not supported on darwin")
+}
+
+func removeBurnCgroup(ctx context.Context, uid string) {
+}
+
+// throttleCgroup is not supported on darwin, there is no cgroup
+func throttleCgroup(ctx context.Context, cgroupRoot, uid string, pid, cpuPercent int) (string, error) {
+	return "", errors.New("cgroup is not supported on darwin")
+}
+
+// checkRealtimeLimit is not supported on darwin, the real-time policies are linux only
+func checkRealtimeLimit(priority int) error {
+	return errors.New("real-time scheduling is not supported on darwin")
+}
+
+func setRealtime(rt *realtimeSpec) error {
+	return errors.New("real-time scheduling is not supported on darwin")
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const FrequencyCpuBin = "chaos_frequencycpu"
+
+const (
+	scalingMaxFreqFile            = "scaling_max_freq"
+	scalingGovernorFile           = "scaling_governor"
+	scalingAvailableGovernorsFile = "scaling_available_governors"
+	cpuinfoMinFreqFile            = "cpuinfo_min_freq"
+	cpuinfoMaxFreqFile            = "cpuinfo_max_freq"
+)
+
+type FrequencyActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewFrequencyActionCommand() spec.ExpActionCommandSpec {
+	return &FrequencyActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "max-freq",
+					Desc: "The max frequency of the cores, unit is kHz, for example 1200000. It can also be a percent of cpuinfo_max_freq, for example 50%",
+				},
+				&spec.ExpFlag{
+					Name: "governor",
+					Desc: "The cpufreq governor of the cores, for example powersave, it must be one of scaling_available_governors",
+				},
+			},
+			ActionExecutor: &cpuFrequencyExecutor{},
+			ActionExample: `
+# Cap the frequency of all online cores to 1.2GHz
+blade create cpu frequency --max-freq 1200000
+
+# Cap the frequency of the cores with index 0-3 to half of their max frequency
+blade create cpu frequency --cpu-list 0-3 --max-freq 50%
+
+# Switch the governor of the core with index 2 to powersave
+blade create cpu frequency --cpu-list 2 --governor powersave
+
+# Cap the frequency of the cores of the socket 1 to 1GHz
+blade create cpu frequency --socket 1 --max-freq 1000000`,
+			ActionPrograms:   []string{FrequencyCpuBin},
+			ActionCategories: []string{category.SystemCpu},
+		},
+	}
+}
+
+func (*FrequencyActionCommand) Name() string {
+	return "frequency"
+}
+
+func (*FrequencyActionCommand) Aliases() []string {
+	return []string{"freq"}
+}
+
+func (*FrequencyActionCommand) ShortDesc() string {
+	return "Slow down cpu cores by cpufreq"
+}
+
+func (f *FrequencyActionCommand) LongDesc() string {
+	if f.ActionLongDesc != "" {
+		return f.ActionLongDesc
+	}
+	return "Cap scaling_max_freq or switch the scaling_governor of the cores in the cpu-list flag, or of all online cores if it is not set, " +
+		"to simulate thermal throttling. The original values of every core are restored on destroy"
+}
+
+type cpuFrequencyExecutor struct {
+	channel spec.Channel
+}
+
+func (*cpuFrequencyExecutor) Name() string {
+	return "frequency"
+}
+
+func (fe *cpuFrequencyExecutor) SetChannel(channel spec.Channel) {
+	fe.channel = channel
+}
+
+func (fe *cpuFrequencyExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if fe.channel == nil {
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		if err := exec.RestoreState(ctx, FrequencyCpuBin, uid); err != nil {
+			log.Errorf(ctx, "restore the cpufreq settings failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("restore the cpufreq settings failed, %v", err))
+		}
+		return spec.Success()
+	}
+
+	maxFreq := model.ActionFlags["max-freq"]
+	governor := model.ActionFlags["governor"]
+	if maxFreq == "" && governor == "" {
+		log.Errorf(ctx, "max-freq|governor is nil")
+		return spec.ResponseFailWithFlags(spec.ParameterLess, "max-freq|governor")
+	}
+
+	cpuList, resp := resolveCpuList(ctx, model.ActionFlags)
+	if resp != nil {
+		return resp
+	}
+	var cores []int
+	if cpuList != "" {
+		if cores, resp = parseCpuList(ctx, cpuList); resp != nil {
+			return resp
+		}
+	} else {
+		online, err := onlineCores()
+		if err != nil {
+			log.Errorf(ctx, "read the online cores failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read the online cores failed, %v", err))
+		}
+		for core := range online {
+			cores = append(cores, core)
+		}
+		sort.Ints(cores)
+	}
+	if exec.StateExists(FrequencyCpuBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, FrequencyCpuBin+"."+uid)
+	}
+
+	// validate every core before changing anything
+	var originals, changes []exec.FileValue
+	for _, core := range cores {
+		cpufreq := filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", core), "cpufreq")
+		if governor != "" {
+			available, err := readSysValue(cpufreq, scalingAvailableGovernorsFile)
+			if err != nil {
+				log.Errorf(ctx, "cpu%d does not support cpufreq, %v", core, err)
+				return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("cpu%d does not support cpufreq, %v", core, err))
+			}
+			if !slices.Contains(strings.Fields(available), governor) {
+				log.Errorf(ctx, "`%s`: governor is not available for cpu%d, available: %s", governor, core, available)
+				return spec.ResponseFailWithFlags(spec.ParameterIllegal, "governor", governor, fmt.Sprintf("it must be one of %s", available))
+			}
+			if resp := appendFrequencyChange(ctx, &originals, &changes, cpufreq, scalingGovernorFile, governor); resp != nil {
+				return resp
+			}
+		}
+		if maxFreq != "" {
+			freq, resp := parseMaxFreq(ctx, cpufreq, maxFreq)
+			if resp != nil {
+				return resp
+			}
+			if resp := appendFrequencyChange(ctx, &originals, &changes, cpufreq, scalingMaxFreqFile, freq); resp != nil {
+				return resp
+			}
+		}
+	}
+
+	if err := exec.SaveState(FrequencyCpuBin, uid, originals); err != nil {
+		log.Errorf(ctx, "save the cpufreq settings failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("save the cpufreq settings failed, %v", err))
+	}
+	for _, change := range changes {
+		if err := os.WriteFile(change.Path, []byte(change.Value), 0o644); err != nil { //nolint:gosec
+			log.Errorf(ctx, "write %s to %s failed, %v", change.Value, change.Path, err)
+			if restoreErr := exec.RestoreState(ctx, FrequencyCpuBin, uid); restoreErr != nil {
+				log.Errorf(ctx, "restore the cpufreq settings failed, %v", restoreErr)
+			}
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("write %s to %s failed, %v", change.Value, change.Path, err))
+		}
+		log.Infof(ctx, "write %s to %s", change.Value, change.Path)
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("cpufreq of cpu %v changed", cores))
+}
+
+// appendFrequencyChange records the original value of the cpufreq file and the value to write
+func appendFrequencyChange(ctx context.Context, originals, changes *[]exec.FileValue, cpufreq, file, value string) *spec.Response {
+	original, err := readSysValue(cpufreq, file)
+	if err != nil {
+		log.Errorf(ctx, "read %s of %s failed, %v", file, cpufreq, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read %s of %s failed, %v", file, cpufreq, err))
+	}
+	path := filepath.Join(cpufreq, file)
+	*originals = append(*originals, exec.FileValue{Path: path, Value: original})
+	*changes = append(*changes, exec.FileValue{Path: path, Value: value})
+	return nil
+}
+
+// parseMaxFreq returns the max-freq in kHz, which must not be lower than cpuinfo_min_freq of the core
+func parseMaxFreq(ctx context.Context, cpufreq, maxFreq string) (string, *spec.Response) {
+	minFreqStr, err := readSysValue(cpufreq, cpuinfoMinFreqFile)
+	if err != nil {
+		log.Errorf(ctx, "read %s of %s failed, %v", cpuinfoMinFreqFile, cpufreq, err)
+		return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read %s of %s failed, %v", cpuinfoMinFreqFile, cpufreq, err))
+	}
+	minFreq, err := strconv.ParseInt(minFreqStr, 10, 64)
+	if err != nil {
+		return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("parse %s of %s failed, %v", cpuinfoMinFreqFile, cpufreq, err))
+	}
+
+	var freq int64
+	if strings.HasSuffix(maxFreq, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(maxFreq, "%"))
+		if err != nil || percent <= 0 || percent > 100 {
+			log.Errorf(ctx, "`%s`: max-freq is illegal, the percent must be a positive integer and not bigger than 100", maxFreq)
+			return "", spec.ResponseFailWithFlags(spec.ParameterIllegal, "max-freq", maxFreq, "the percent must be a positive integer and not bigger than 100")
+		}
+		cpuinfoMaxFreq, err := readSysValue(cpufreq, cpuinfoMaxFreqFile)
+		if err != nil {
+			return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read %s of %s failed, %v", cpuinfoMaxFreqFile, cpufreq, err))
+		}
+		if freq, err = strconv.ParseInt(cpuinfoMaxFreq, 10, 64); err != nil {
+			return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("parse %s of %s failed, %v", cpuinfoMaxFreqFile, cpufreq, err))
+		}
+		freq = freq * int64(percent) / 100
+		// a percent lower than the hardware minimum is raised to it
+		if freq < minFreq {
+			freq = minFreq
+		}
+	} else {
+		if freq, err = strconv.ParseInt(maxFreq, 10, 64); err != nil || freq <= 0 {
+			log.Errorf(ctx, "`%s`: max-freq is illegal, it must be a positive integer", maxFreq)
+			return "", spec.ResponseFailWithFlags(spec.ParameterIllegal, "max-freq", maxFreq, "it must be a positive integer")
+		}
+		if freq < minFreq {
+			log.Errorf(ctx, "`%s`: max-freq is lower than %s of %s", maxFreq, cpuinfoMinFreqFile, cpufreq)
+			return "", spec.ResponseFailWithFlags(spec.ParameterIllegal, "max-freq", maxFreq, fmt.Sprintf("it must not be lower than %d", minFreq))
+		}
+	}
+	return strconv.FormatInt(freq, 10), nil
+}
+
+func readSysValue(dir, file string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMaxFreq(t *testing.T) {
+	cpufreq := t.TempDir()
+	for file, value := range map[string]string{cpuinfoMinFreqFile: "800000\n", cpuinfoMaxFreqFile: "3000000\n"} {
+		if err := os.WriteFile(filepath.Join(cpufreq, file), []byte(value), 0o644); err != nil {
+			t.Fatalf("write %s failed, %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		maxFreq string
+		expect  string
+		illegal bool
+	}{
+		{"1200000", "1200000", false},
+		{"50%", "1500000", false},
+		{"10%", "800000", false},
+		{"100%", "3000000", false},
+		{"700000", "", true},
+		{"0%", "", true},
+		{"101%", "", true},
+		{"1.2GHz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.maxFreq, func(t *testing.T) {
+			got, resp := parseMaxFreq(context.Background(), cpufreq, tt.maxFreq)
+			if tt.illegal {
+				if resp == nil {
+					t.Errorf("expected %s to be illegal, got %s", tt.maxFreq, got)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("unexpected response: %s", resp.Err)
+			}
+			if got != tt.expect {
+				t.Errorf("unexpected max-freq: %s, expected: %s", got, tt.expect)
+			}
+		})
+	}
+}
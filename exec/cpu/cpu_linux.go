@@ -29,6 +29,7 @@ import (
 	"github.com/chaosblade-io/chaosblade-spec-go/log"
 	containerdCgroups "github.com/containerd/cgroups"
 	"github.com/shirou/gopsutil/cpu"
+	"golang.org/x/sys/unix"
 
 	"github.com/chaosblade-io/chaosblade-exec-os/exec"
 	"github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
@@ -205,3 +206,43 @@ func getUsed(ctx context.Context, percpu bool, cpuIndex int) float64 {
 	}
 	return totalCpuPercent[0]
 }
+
+// bindToCore sets the affinity of the calling thread to the core, the caller must lock its goroutine to the thread
+func bindToCore(core int) error {
+	var set unix.CPUSet
+	set.Set(core)
+	return unix.SchedSetaffinity(0, &set)
+}
+
+// capSysNice is the bit of CAP_SYS_NICE in the capability sets
+const capSysNice = 23
+
+// checkRealtimeLimit returns an error if the burner is not permitted to use the real-time priority,
+// the RLIMIT_RTPRIO is ignored if the burner has CAP_SYS_NICE.
+func checkRealtimeLimit(priority int) error {
+	if exec.HasCapability(capSysNice) {
+		return nil
+	}
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_RTPRIO, &limit); err != nil {
+		return fmt.Errorf("get RLIMIT_RTPRIO failed, %v", err)
+	}
+	if uint64(priority) > limit.Cur {
+		return fmt.Errorf("it exceeds RLIMIT_RTPRIO %d and CAP_SYS_NICE is not granted", limit.Cur)
+	}
+	return nil
+}
+
+// setRealtime schedules the calling thread by the real-time policy, the children forked by
+// the thread are reset to the normal policy.
+func setRealtime(rt *realtimeSpec) error {
+	policy := uint32(unix.SCHED_FIFO)
+	if rt.policy == SchedRR {
+		policy = unix.SCHED_RR
+	}
+	return unix.SchedSetAttr(0, &unix.SchedAttr{
+		Policy:   policy,
+		Flags:    unix.SCHED_FLAG_RESET_ON_FORK,
+		Priority: uint32(rt.priority),
+	}, 0)
+}
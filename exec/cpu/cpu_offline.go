@@ -0,0 +1,208 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const OfflineCpuBin = "chaos_offlinecpu"
+
+// cpuSysPath is the sysfs directory of the cpus
+var cpuSysPath = "/sys/devices/system/cpu"
+
+type OfflineActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewOfflineActionCommand() spec.ExpActionCommandSpec {
+	return &OfflineActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags:    []spec.ExpFlagSpec{},
+			ActionExecutor: &cpuOfflineExecutor{},
+			ActionExample: `
+# Offline the cores with index 2 and 3
+blade create cpu offline --cpu-list 2,3
+
+# Offline the cores with indexes 4-7
+blade create cpu offline --cpu-list 4-7
+
+# Disable smt by offline the hyperthread siblings of the cores with indexes 0-3
+blade create cpu offline --smt-siblings-of 0-3`,
+			ActionPrograms:   []string{OfflineCpuBin},
+			ActionCategories: []string{category.SystemCpu},
+		},
+	}
+}
+
+func (*OfflineActionCommand) Name() string {
+	return "offline"
+}
+
+func (*OfflineActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*OfflineActionCommand) ShortDesc() string {
+	return "Offline cpu cores"
+}
+
+func (o *OfflineActionCommand) LongDesc() string {
+	if o.ActionLongDesc != "" {
+		return o.ActionLongDesc
+	}
+	return "Hot-unplug the cores in the cpu-list flag by /sys/devices/system/cpu/cpuN/online. " +
+		"The cpu0 and the last online core can not be offline. All of the offline cores are brought back online on destroy"
+}
+
+type cpuOfflineExecutor struct {
+	channel spec.Channel
+}
+
+func (*cpuOfflineExecutor) Name() string {
+	return "offline"
+}
+
+func (oe *cpuOfflineExecutor) SetChannel(channel spec.Channel) {
+	oe.channel = channel
+}
+
+func (oe *cpuOfflineExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if oe.channel == nil {
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		if err := exec.RestoreState(ctx, OfflineCpuBin, uid); err != nil {
+			log.Errorf(ctx, "bring the cores back online failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("bring the cores back online failed, %v", err))
+		}
+		return spec.Success()
+	}
+
+	cpuList, resp := resolveCpuList(ctx, model.ActionFlags)
+	if resp != nil {
+		return resp
+	}
+	if cpuList == "" {
+		log.Errorf(ctx, "cpu-list is nil")
+		return spec.ResponseFailWithFlags(spec.ParameterLess, "cpu-list")
+	}
+	cores, resp := parseCpuList(ctx, cpuList)
+	if resp != nil {
+		return resp
+	}
+	if exec.StateExists(OfflineCpuBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, OfflineCpuBin+"."+uid)
+	}
+	offline, resp := checkOfflineCores(ctx, cores)
+	if resp != nil {
+		return resp
+	}
+	if len(offline) == 0 {
+		return spec.ReturnSuccess("the cores are already offline")
+	}
+	return offlineCores(ctx, uid, offline)
+}
+
+// checkOfflineCores returns the online cores in the list, the cpu0 and the last online core are refused
+func checkOfflineCores(ctx context.Context, cores []int) ([]int, *spec.Response) {
+	online, err := onlineCores()
+	if err != nil {
+		log.Errorf(ctx, "read the online cores failed, %v", err)
+		return nil, spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read the online cores failed, %v", err))
+	}
+	offline := make([]int, 0, len(cores))
+	for _, core := range cores {
+		if core == 0 {
+			log.Errorf(ctx, "cpu0 can not be offline")
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", "0", "cpu0 can not be offline")
+		}
+		if !online[core] {
+			log.Infof(ctx, "cpu%d is already offline, skip it", core)
+			continue
+		}
+		if !util.IsExist(filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", core), "online")) {
+			log.Errorf(ctx, "cpu%d does not support hotplug", core)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", strconv.Itoa(core), "the core does not support hotplug")
+		}
+		offline = append(offline, core)
+		delete(online, core)
+	}
+	if len(online) == 0 {
+		log.Errorf(ctx, "all of the online cores are in the cpu-list")
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-list", fmt.Sprint(cores), "the last online core can not be offline")
+	}
+	return offline, nil
+}
+
+// onlineCores reads the online cores from /sys/devices/system/cpu/online, the format is 0-3,5
+func onlineCores() (map[int]bool, error) {
+	content, err := os.ReadFile(filepath.Join(cpuSysPath, "online"))
+	if err != nil {
+		return nil, err
+	}
+	cores, err := util.ParseIntegerListToStringSlice("online", strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	online := make(map[int]bool, len(cores))
+	for _, core := range cores {
+		index, err := strconv.Atoi(core)
+		if err != nil {
+			return nil, err
+		}
+		online[index] = true
+	}
+	return online, nil
+}
+
+// offlineCores saves the online state of the cores before writing 0 to them, a failed core stops
+// the experiment and the cores offline before are brought back online.
+func offlineCores(ctx context.Context, uid string, cores []int) *spec.Response {
+	values := make([]exec.FileValue, len(cores))
+	for i, core := range cores {
+		values[i] = exec.FileValue{Path: filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", core), "online"), Value: "1"}
+	}
+	if err := exec.SaveState(OfflineCpuBin, uid, values); err != nil {
+		log.Errorf(ctx, "save the online state failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("save the online state failed, %v", err))
+	}
+	for _, value := range values {
+		if err := os.WriteFile(value.Path, []byte("0"), 0o644); err != nil { //nolint:gosec
+			log.Errorf(ctx, "write 0 to %s failed, %v", value.Path, err)
+			if restoreErr := exec.RestoreState(ctx, OfflineCpuBin, uid); restoreErr != nil {
+				log.Errorf(ctx, "bring the cores back online failed, %v", restoreErr)
+			}
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("write 0 to %s failed, %v", value.Path, err))
+		}
+		log.Infof(ctx, "%s is offline", value.Path)
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("cpu %v offline", cores))
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckOfflineCores(t *testing.T) {
+	cpuSysPath = t.TempDir()
+	defer func() { cpuSysPath = "/sys/devices/system/cpu" }()
+	// cpu0 has no online file like most of the hosts
+	for core := 1; core <= 3; core++ {
+		dir := filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", core))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("create %s failed, %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "online"), []byte("1"), 0o644); err != nil {
+			t.Fatalf("write online file failed, %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		online  string
+		cores   []int
+		expect  []int
+		refused bool
+	}{
+		{"offline one core", "0-3", []int{2}, []int{2}, false},
+		{"skip the offline core", "0-2", []int{2, 3}, []int{2}, false},
+		{"refuse cpu0", "0-3", []int{0, 1}, nil, true},
+		{"refuse the last online core", "1-2", []int{1, 2}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(cpuSysPath, "online"), []byte(tt.online+"\n"), 0o644); err != nil {
+				t.Fatalf("write online file failed, %v", err)
+			}
+			got, resp := checkOfflineCores(context.Background(), tt.cores)
+			if tt.refused {
+				if resp == nil {
+					t.Errorf("expected %v to be refused", tt.cores)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("unexpected response: %s", resp.Err)
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("unexpected cores: %v, expected: %v", got, tt.expect)
+			}
+		})
+	}
+}
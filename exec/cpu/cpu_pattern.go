@@ -0,0 +1,245 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+const (
+	PatternSine         = "sine"
+	PatternSquare       = "square"
+	PatternSawtooth     = "sawtooth"
+	PatternStepSchedule = "step-schedule"
+	PatternRandomWalk   = "random-walk"
+)
+
+const defaultPatternPeriod = 60
+
+// waveform returns the target cpu percent at a point of the experiment
+type waveform interface {
+	Percent(elapsed time.Duration) float64
+}
+
+// loadPattern holds the parsed pattern flags of cpu fullload
+type loadPattern struct {
+	name   string
+	period int
+	min    float64
+	max    float64
+	steps  []float64
+}
+
+// parseLoadPattern parses the pattern flags, the min and max percent default to
+// cpu-percent -/+ amplitude if amplitude is set, otherwise to 0 and cpu-percent.
+func parseLoadPattern(ctx context.Context, flags map[string]string, cpuPercent int) (*loadPattern, *spec.Response) {
+	name := flags["pattern"]
+	if name == "" {
+		return nil, nil
+	}
+	switch name {
+	case PatternSine, PatternSquare, PatternSawtooth, PatternStepSchedule, PatternRandomWalk:
+	default:
+		log.Errorf(ctx, "`%s`: pattern is illegal", name)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "pattern", name,
+			"it must be one of sine, square, sawtooth, step-schedule, random-walk")
+	}
+
+	pattern := &loadPattern{name: name, period: defaultPatternPeriod, min: 0, max: float64(cpuPercent)}
+	if periodStr := flags["period"]; periodStr != "" {
+		period, err := strconv.Atoi(periodStr)
+		if err != nil || period <= 0 {
+			log.Errorf(ctx, "`%s`: period is illegal, it must be a positive integer", periodStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "period", periodStr, "it must be a positive integer")
+		}
+		pattern.period = period
+	}
+
+	if amplitudeStr := flags["amplitude"]; amplitudeStr != "" {
+		amplitude, err := strconv.Atoi(amplitudeStr)
+		if err != nil || amplitude < 0 || amplitude > 100 {
+			log.Errorf(ctx, "`%s`: amplitude is illegal, it must be a positive integer and not bigger than 100", amplitudeStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "amplitude", amplitudeStr, "it must be a positive integer and not bigger than 100")
+		}
+		pattern.min = math.Max(float64(cpuPercent-amplitude), 0)
+		pattern.max = math.Min(float64(cpuPercent+amplitude), 100)
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value *float64
+	}{{"min-percent", &pattern.min}, {"max-percent", &pattern.max}} {
+		valueStr := flags[bound.name]
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 || value > 100 {
+			log.Errorf(ctx, "`%s`: %s is illegal, it must be a positive integer and not bigger than 100", valueStr, bound.name)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, bound.name, valueStr, "it must be a positive integer and not bigger than 100")
+		}
+		*bound.value = float64(value)
+	}
+	if pattern.min > pattern.max {
+		log.Errorf(ctx, "min-percent %.0f is bigger than max-percent %.0f", pattern.min, pattern.max)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "min-percent", pattern.min, "it must not be bigger than max-percent")
+	}
+
+	if name == PatternStepSchedule {
+		stepsStr := flags["steps"]
+		if stepsStr == "" {
+			log.Errorf(ctx, "steps is required by the step-schedule pattern")
+			return nil, spec.ResponseFailWithFlags(spec.ParameterLess, "steps")
+		}
+		for _, s := range strings.Split(stepsStr, ",") {
+			step, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || step < 0 || step > 100 {
+				log.Errorf(ctx, "`%s`: steps is illegal, every step must be a positive integer and not bigger than 100", stepsStr)
+				return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "steps", stepsStr, "every step must be a positive integer and not bigger than 100")
+			}
+			pattern.steps = append(pattern.steps, float64(step))
+		}
+	}
+	return pattern, nil
+}
+
+// peak returns the highest percent the pattern reaches, which is the highest step of the step
+// schedule, or the max of the waveform. The cpu-percent is ignored once a pattern is set.
+func (p *loadPattern) peak() int {
+	if p.name != PatternStepSchedule {
+		return int(math.Ceil(p.max))
+	}
+	var peak float64
+	for _, step := range p.steps {
+		peak = math.Max(peak, step)
+	}
+	return int(math.Ceil(peak))
+}
+
+// scale applies the cgroup quota ratio to the pattern bounds, the same as cpu-percent
+func (p *loadPattern) scale(ratio float64) {
+	if ratio == 1.0 {
+		return
+	}
+	p.min = math.Min(p.min*ratio, 100)
+	p.max = math.Min(math.Max(p.max*ratio, 1), 100)
+	for i := range p.steps {
+		p.steps[i] = math.Min(p.steps[i]*ratio, 100)
+	}
+}
+
+func (p *loadPattern) waveform() waveform {
+	period := time.Duration(p.period) * time.Second
+	switch p.name {
+	case PatternSine:
+		return &sineWave{period: period, min: p.min, max: p.max}
+	case PatternSquare:
+		return &squareWave{period: period, min: p.min, max: p.max}
+	case PatternSawtooth:
+		return &sawtoothWave{period: period, min: p.min, max: p.max}
+	case PatternStepSchedule:
+		return &stepSchedule{period: period, steps: p.steps}
+	default:
+		return &randomWalk{
+			period:  period,
+			min:     p.min,
+			max:     p.max,
+			current: (p.min + p.max) / 2,
+			rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+	}
+}
+
+type sineWave struct {
+	period   time.Duration
+	min, max float64
+}
+
+func (w *sineWave) Percent(elapsed time.Duration) float64 {
+	phase := 2 * math.Pi * float64(elapsed%w.period) / float64(w.period)
+	return (w.min+w.max)/2 + (w.max-w.min)/2*math.Sin(phase)
+}
+
+type squareWave struct {
+	period   time.Duration
+	min, max float64
+}
+
+func (w *squareWave) Percent(elapsed time.Duration) float64 {
+	if elapsed%w.period < w.period/2 {
+		return w.max
+	}
+	return w.min
+}
+
+type sawtoothWave struct {
+	period   time.Duration
+	min, max float64
+}
+
+func (w *sawtoothWave) Percent(elapsed time.Duration) float64 {
+	return w.min + (w.max-w.min)*float64(elapsed%w.period)/float64(w.period)
+}
+
+// stepSchedule holds every step for a period and starts over after the last one
+type stepSchedule struct {
+	period time.Duration
+	steps  []float64
+}
+
+func (w *stepSchedule) Percent(elapsed time.Duration) float64 {
+	return w.steps[int(elapsed/w.period)%len(w.steps)]
+}
+
+// randomWalk moves the target randomly between min and max, the speed is limited
+// so that walking across the whole range takes at least a period.
+type randomWalk struct {
+	period   time.Duration
+	min, max float64
+	current  float64
+	last     time.Duration
+	rand     *rand.Rand
+}
+
+func (w *randomWalk) Percent(elapsed time.Duration) float64 {
+	maxStep := (w.max - w.min) * float64(elapsed-w.last) / float64(w.period)
+	w.last = elapsed
+	w.current += (w.rand.Float64()*2 - 1) * maxStep
+	w.current = math.Min(math.Max(w.current, w.min), w.max)
+	return w.current
+}
+
+// follow makes the burn target track the waveform, it is re-evaluated every second.
+func follow(ctx context.Context, w waveform, slopePercent *float64) {
+	startTime := time.Now()
+	*slopePercent = w.Percent(0)
+	ticker := time.NewTicker(time.Second)
+	go func() {
+		for range ticker.C {
+			*slopePercent = w.Percent(time.Since(startTime))
+			log.Debugf(ctx, "cpu load pattern target: %f", *slopePercent)
+		}
+	}()
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+func TestWaveformPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		elapsed []time.Duration
+		expect  []float64
+	}{
+		{
+			name:    "sine",
+			flags:   map[string]string{"pattern": "sine", "period": "40", "min-percent": "20", "max-percent": "80"},
+			elapsed: []time.Duration{0, 10 * time.Second, 30 * time.Second, 40 * time.Second},
+			expect:  []float64{50, 80, 20, 50},
+		},
+		{
+			name:    "square with amplitude",
+			flags:   map[string]string{"pattern": "square", "period": "60", "amplitude": "30"},
+			elapsed: []time.Duration{0, 29 * time.Second, 30 * time.Second, 61 * time.Second},
+			expect:  []float64{90, 90, 30, 90},
+		},
+		{
+			name:    "sawtooth",
+			flags:   map[string]string{"pattern": "sawtooth", "period": "10", "max-percent": "100"},
+			elapsed: []time.Duration{0, 5 * time.Second, 10 * time.Second},
+			expect:  []float64{0, 50, 0},
+		},
+		{
+			name:    "step-schedule",
+			flags:   map[string]string{"pattern": "step-schedule", "period": "5", "steps": "20,80,50"},
+			elapsed: []time.Duration{0, 5 * time.Second, 12 * time.Second, 15 * time.Second},
+			expect:  []float64{20, 80, 50, 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, resp := parseLoadPattern(context.Background(), tt.flags, 60)
+			if resp != nil {
+				t.Fatalf("unexpected response: %s", resp.Err)
+			}
+			w := pattern.waveform()
+			for i, elapsed := range tt.elapsed {
+				if got := w.Percent(elapsed); math.Abs(got-tt.expect[i]) > 0.001 {
+					t.Errorf("Percent(%s) = %f, expected: %f", elapsed, got, tt.expect[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRandomWalkBounds(t *testing.T) {
+	pattern, resp := parseLoadPattern(context.Background(),
+		map[string]string{"pattern": "random-walk", "period": "10", "min-percent": "30", "max-percent": "60"}, 100)
+	if resp != nil {
+		t.Fatalf("unexpected response: %s", resp.Err)
+	}
+	w := pattern.waveform()
+	last := w.Percent(0)
+	for i := 1; i <= 1000; i++ {
+		got := w.Percent(time.Duration(i) * time.Second)
+		if got < 30 || got > 60 {
+			t.Fatalf("random walk left the range: %f", got)
+		}
+		if math.Abs(got-last) > 3.0001 {
+			t.Fatalf("random walk moved too fast: %f -> %f", last, got)
+		}
+		last = got
+	}
+}
+
+func TestParseLoadPatternIllegal(t *testing.T) {
+	tests := []map[string]string{
+		{"pattern": "triangle"},
+		{"pattern": "sine", "period": "0"},
+		{"pattern": "sine", "min-percent": "80", "max-percent": "20"},
+		{"pattern": "step-schedule"},
+		{"pattern": "step-schedule", "steps": "20,120"},
+	}
+	for _, flags := range tests {
+		if _, resp := parseLoadPattern(context.Background(), flags, 60); resp == nil {
+			t.Errorf("expected %v to be illegal", flags)
+		}
+	}
+}
+
+func TestLoadPatternPeak(t *testing.T) {
+	tests := []struct {
+		flags  map[string]string
+		expect int
+	}{
+		{map[string]string{"pattern": "sine", "amplitude": "30"}, 90},
+		{map[string]string{"pattern": "square", "max-percent": "40"}, 40},
+		{map[string]string{"pattern": "step-schedule", "steps": "20,100,50"}, 100},
+		{map[string]string{"pattern": "step-schedule", "steps": "20,30"}, 30},
+		{map[string]string{"pattern": "sine", "min-percent": "20", "max-percent": "50"}, 50},
+	}
+	for _, tt := range tests {
+		pattern, resp := parseLoadPattern(context.Background(), tt.flags, 60)
+		if resp != nil {
+			t.Fatalf("parse %v failed, %s", tt.flags, resp.Err)
+		}
+		if peak := pattern.peak(); peak != tt.expect {
+			t.Errorf("unexpected peak of %v: %d, expected: %d", tt.flags, peak, tt.expect)
+		}
+	}
+}
+
+func TestLoadPatternWithSchedPolicy(t *testing.T) {
+	tests := []struct {
+		flags   map[string]string
+		refused bool
+	}{
+		{map[string]string{"pattern": "sine", "min-percent": "20", "max-percent": "80"}, false},
+		{map[string]string{"pattern": "step-schedule", "steps": "30,60"}, false},
+		{map[string]string{"pattern": "step-schedule", "steps": "30,100"}, true},
+		{map[string]string{"pattern": "square"}, true},
+	}
+	for _, tt := range tests {
+		tt.flags["sched-policy"] = SchedFIFO
+		// the cpu-percent is 100 if it is not set
+		pattern, resp := parseLoadPattern(context.Background(), tt.flags, 100)
+		if resp != nil {
+			t.Fatalf("parse %v failed, %s", tt.flags, resp.Err)
+		}
+		_, resp = parseRealtimeSpec(context.Background(), tt.flags, pattern.peak())
+		if resp != nil && resp.Code == spec.ParameterInvalid.Code {
+			t.Skipf("the real-time policy is not permitted, %s", resp.Err)
+		}
+		if refused := resp != nil; refused != tt.refused {
+			t.Errorf("unexpected refused of %v: %t, expected: %t", tt.flags, refused, tt.refused)
+		}
+	}
+}
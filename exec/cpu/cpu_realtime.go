@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+const (
+	SchedFIFO = "fifo"
+	SchedRR   = "rr"
+)
+
+const (
+	minRealtimePriority = 1
+	maxRealtimePriority = 99
+	// maxRealtimePercent is the ceiling of the peak percent of the real-time burner, it must sleep
+	// part of every period at the peak
+	maxRealtimePercent = 100
+)
+
+// realtimeSpec holds the parsed real-time scheduling flags of cpu fullload
+type realtimeSpec struct {
+	policy   string
+	priority int
+}
+
+// parseRealtimeSpec parses the sched-policy and sched-priority flags, it returns nil if the
+// burner runs by the normal policy. The burner must sleep part of every period at the peak
+// percent of cpu-percent and the pattern, so that the host is still recoverable, and the
+// priority must be permitted by RLIMIT_RTPRIO.
+func parseRealtimeSpec(ctx context.Context, flags map[string]string, peakPercent int) (*realtimeSpec, *spec.Response) {
+	policy := flags["sched-policy"]
+	priorityStr := flags["sched-priority"]
+	if policy == "" {
+		if priorityStr != "" {
+			log.Errorf(ctx, "sched-policy is nil")
+			return nil, spec.ResponseFailWithFlags(spec.ParameterLess, "sched-policy")
+		}
+		return nil, nil
+	}
+	if policy != SchedFIFO && policy != SchedRR {
+		log.Errorf(ctx, "`%s`: sched-policy is illegal, it must be %s or %s", policy, SchedFIFO, SchedRR)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "sched-policy", policy, "it must be fifo or rr")
+	}
+	rt := &realtimeSpec{policy: policy, priority: minRealtimePriority}
+	if priorityStr != "" {
+		priority, err := strconv.Atoi(priorityStr)
+		if err != nil || priority < minRealtimePriority || priority > maxRealtimePriority {
+			log.Errorf(ctx, "`%s`: sched-priority is illegal, it must be an integer between %d and %d",
+				priorityStr, minRealtimePriority, maxRealtimePriority)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "sched-priority", priorityStr,
+				fmt.Sprintf("it must be an integer between %d and %d", minRealtimePriority, maxRealtimePriority))
+		}
+		rt.priority = priority
+	}
+	if peakPercent >= maxRealtimePercent {
+		log.Errorf(ctx, "`%d`: the peak of cpu-percent and the pattern is illegal, the real-time burner must sleep part of every period", peakPercent)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-percent", strconv.Itoa(peakPercent),
+			fmt.Sprintf("the peak of cpu-percent, the pattern and its steps must be less than %d with sched-policy, the real-time burner must sleep part of every period", maxRealtimePercent))
+	}
+	if err := checkRealtimeLimit(rt.priority); err != nil {
+		log.Errorf(ctx, "`%d`: sched-priority is not permitted, %v", rt.priority, err)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterInvalid, "sched-priority", strconv.Itoa(rt.priority), err)
+	}
+	return rt, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseRealtimeSpec(t *testing.T) {
+	rt, resp := parseRealtimeSpec(context.Background(), map[string]string{}, 100)
+	if rt != nil || resp != nil {
+		t.Fatalf("expected the normal policy without sched-policy")
+	}
+
+	tests := []struct {
+		name       string
+		flags      map[string]string
+		cpuPercent int
+	}{
+		{"priority without policy", map[string]string{"sched-priority": "10"}, 50},
+		{"unknown policy", map[string]string{"sched-policy": "deadline"}, 50},
+		{"priority too low", map[string]string{"sched-policy": SchedFIFO, "sched-priority": "0"}, 50},
+		{"priority too high", map[string]string{"sched-policy": SchedRR, "sched-priority": "100"}, 50},
+		{"full duty cycle", map[string]string{"sched-policy": SchedFIFO}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, resp := parseRealtimeSpec(context.Background(), tt.flags, tt.cpuPercent); resp == nil {
+				t.Errorf("expected %v to be refused", tt.flags)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const ThrottleCpuBin = "chaos_throttlecpu"
+
+type ThrottleActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewThrottleActionCommand() spec.ExpActionCommandSpec {
+	return &ThrottleActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "pid",
+					Desc: "The pid of the process whose cgroup is throttled, the pid is in the host pid namespace",
+				},
+				&spec.ExpFlag{
+					Name: "process",
+					Desc: "The name of the process whose cgroup is throttled, the first matched process is used",
+				},
+			},
+			ActionFlags:    []spec.ExpFlagSpec{},
+			ActionExecutor: &cpuThrottleExecutor{},
+			ActionExample: `
+# Lower the cpu quota of the cgroup of the process 1234 to 20% of its current quota
+blade create cpu throttle --pid 1234 --cpu-percent 20
+
+# Lower the cpu quota of the cgroup of the nginx process to 50% of its current quota
+blade create cpu throttle --process nginx --cpu-percent 50`,
+			ActionPrograms:   []string{ThrottleCpuBin},
+			ActionCategories: []string{category.SystemCpu},
+		},
+	}
+}
+
+func (*ThrottleActionCommand) Name() string {
+	return "throttle"
+}
+
+func (*ThrottleActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*ThrottleActionCommand) ShortDesc() string {
+	return "Throttle the cpu quota of a process cgroup"
+}
+
+func (t *ThrottleActionCommand) LongDesc() string {
+	if t.ActionLongDesc != "" {
+		return t.ActionLongDesc
+	}
+	return "Lower the cpu quota (cpu.max of cgroup v2 or cpu.cfs_quota_us of cgroup v1) of the cgroup which the target process belongs to, " +
+		"the cpu-percent flag is the percent of the current quota, or of all cores if the quota is unlimited. The original quota is restored on destroy"
+}
+
+type cpuThrottleExecutor struct {
+	channel spec.Channel
+}
+
+func (*cpuThrottleExecutor) Name() string {
+	return "throttle"
+}
+
+func (te *cpuThrottleExecutor) SetChannel(channel spec.Channel) {
+	te.channel = channel
+}
+
+func (te *cpuThrottleExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if te.channel == nil {
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		return te.stop(ctx, uid)
+	}
+
+	cpuPercentStr := model.ActionFlags["cpu-percent"]
+	if cpuPercentStr == "" {
+		log.Errorf(ctx, "cpu-percent is nil")
+		return spec.ResponseFailWithFlags(spec.ParameterLess, "cpu-percent")
+	}
+	cpuPercent, err := strconv.Atoi(cpuPercentStr)
+	if err != nil || cpuPercent <= 0 || cpuPercent > 100 {
+		log.Errorf(ctx, "`%s`: cpu-percent is illegal, it must be a positive integer and not bigger than 100", cpuPercentStr)
+		return spec.ResponseFailWithFlags(spec.ParameterIllegal, "cpu-percent", cpuPercentStr, "it must be a positive integer and not bigger than 100")
+	}
+
+	pid, resp := exec.TargetPid(ctx, te.channel, model)
+	if resp != nil {
+		return resp
+	}
+	if exec.StateExists(ThrottleCpuBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, ThrottleCpuBin+"."+uid)
+	}
+	quota, err := throttleCgroup(ctx, model.ActionFlags["cgroup-root"], uid, pid, cpuPercent)
+	if err != nil {
+		log.Errorf(ctx, "throttle the cgroup of pid %d failed, %v", pid, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("throttle the cgroup of pid %d failed, %v", pid, err))
+	}
+	return spec.ReturnSuccess(quota)
+}
+
+func (te *cpuThrottleExecutor) stop(ctx context.Context, uid string) *spec.Response {
+	if err := exec.RestoreState(ctx, ThrottleCpuBin, uid); err != nil {
+		log.Errorf(ctx, "restore the cpu quota failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("restore the cpu quota failed, %v", err))
+	}
+	return spec.Success()
+}
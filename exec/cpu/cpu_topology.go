@@ -0,0 +1,215 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+)
+
+// topologyFlags are the selectors resolved from /sys/devices/system/cpu/cpuN/topology
+var topologyFlags = []string{"numa-node", "socket", "physical-cores-only", "smt-siblings-of"}
+
+// cpuTopology is the position of a logical cpu in the machine
+type cpuTopology struct {
+	node     int
+	socket   int
+	siblings []int
+}
+
+// topologySelected returns true if any of the topology selectors is set
+func topologySelected(flags map[string]string) bool {
+	for _, name := range topologyFlags {
+		if value := flags[name]; value != "" && value != "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCpuList returns the cpu-list flag narrowed by the topology selectors, all of the selectors
+// and the cpu-list flag must be matched. Only the online cpus are selected.
+func resolveCpuList(ctx context.Context, flags map[string]string) (string, *spec.Response) {
+	cpuList := flags["cpu-list"]
+	if !topologySelected(flags) {
+		return cpuList, nil
+	}
+	online, err := onlineCores()
+	if err != nil {
+		log.Errorf(ctx, "read the online cores failed, %v", err)
+		return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read the online cores failed, %v", err))
+	}
+
+	var candidates []int
+	if cpuList != "" {
+		var resp *spec.Response
+		if candidates, resp = parseCpuList(ctx, cpuList); resp != nil {
+			return "", resp
+		}
+	} else {
+		for cpu := range online {
+			candidates = append(candidates, cpu)
+		}
+		sort.Ints(candidates)
+	}
+
+	nodes, resp := parseSelector(ctx, flags, "numa-node")
+	if resp != nil {
+		return "", resp
+	}
+	sockets, resp := parseSelector(ctx, flags, "socket")
+	if resp != nil {
+		return "", resp
+	}
+	siblingsOf, resp := parseSelector(ctx, flags, "smt-siblings-of")
+	if resp != nil {
+		return "", resp
+	}
+	var siblings map[int]bool
+	if siblingsOf != nil {
+		siblings = make(map[int]bool)
+		for cpu := range siblingsOf {
+			topology, err := readTopology(cpu)
+			if err != nil {
+				log.Errorf(ctx, "read the topology of cpu%d failed, %v", cpu, err)
+				return "", spec.ResponseFailWithFlags(spec.ParameterInvalid, "smt-siblings-of", flags["smt-siblings-of"], err)
+			}
+			for _, sibling := range topology.siblings {
+				if !siblingsOf[sibling] {
+					siblings[sibling] = true
+				}
+			}
+		}
+	}
+	physicalOnly := flags["physical-cores-only"] == "true"
+	cores := make(map[int]bool)
+
+	var selected []string
+	for _, cpu := range candidates {
+		if !online[cpu] {
+			continue
+		}
+		topology, err := readTopology(cpu)
+		if err != nil {
+			log.Errorf(ctx, "read the topology of cpu%d failed, %v", cpu, err)
+			return "", spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read the topology of cpu%d failed, %v", cpu, err))
+		}
+		if (nodes != nil && !nodes[topology.node]) || (sockets != nil && !sockets[topology.socket]) ||
+			(siblings != nil && !siblings[cpu]) {
+			continue
+		}
+		// the first selected thread stands for the physical core, which is known by its first sibling
+		if physicalOnly {
+			if cores[topology.siblings[0]] {
+				continue
+			}
+			cores[topology.siblings[0]] = true
+		}
+		selected = append(selected, strconv.Itoa(cpu))
+	}
+	if len(selected) == 0 {
+		var selectors []string
+		for _, name := range append([]string{"cpu-list"}, topologyFlags...) {
+			if value := flags[name]; value != "" && value != "false" {
+				selectors = append(selectors, name+"="+value)
+			}
+		}
+		log.Errorf(ctx, "no online cpu matches the topology selectors: %s", strings.Join(selectors, " "))
+		return "", spec.ResponseFailWithFlags(spec.ParameterInvalid, strings.Join(topologyFlags, "|"), strings.Join(selectors, " "),
+			"no online cpu matches them")
+	}
+	log.Infof(ctx, "cpus selected by the topology: %s", strings.Join(selected, ","))
+	return strings.Join(selected, ","), nil
+}
+
+// parseSelector parses a list selector, for example 0-1,3, it returns nil if the selector is not set
+func parseSelector(ctx context.Context, flags map[string]string, name string) (map[int]bool, *spec.Response) {
+	value := flags[name]
+	if value == "" {
+		return nil, nil
+	}
+	items, err := util.ParseIntegerListToStringSlice(name, value)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: %s is illegal, %s", value, name, err.Error())
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, name, value, err.Error())
+	}
+	selector := make(map[int]bool, len(items))
+	for _, item := range items {
+		index, err := strconv.Atoi(item)
+		if err != nil {
+			log.Errorf(ctx, "`%s`: %s is illegal, %s", item, name, err.Error())
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, name, item, err.Error())
+		}
+		selector[index] = true
+	}
+	return selector, nil
+}
+
+// readTopology reads the numa node, the socket and the smt siblings of the cpu from sysfs
+func readTopology(cpu int) (*cpuTopology, error) {
+	cpuPath := filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", cpu))
+	socket, err := readSysValue(filepath.Join(cpuPath, "topology"), "physical_package_id")
+	if err != nil {
+		return nil, err
+	}
+	topology := &cpuTopology{}
+	if topology.socket, err = strconv.Atoi(socket); err != nil {
+		return nil, err
+	}
+	// core_cpus_list replaces thread_siblings_list since linux 5.2
+	siblings, err := readSysValue(filepath.Join(cpuPath, "topology"), "core_cpus_list")
+	if err != nil {
+		if siblings, err = readSysValue(filepath.Join(cpuPath, "topology"), "thread_siblings_list"); err != nil {
+			return nil, err
+		}
+	}
+	items, err := util.ParseIntegerListToStringSlice("siblings", siblings)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		sibling, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		topology.siblings = append(topology.siblings, sibling)
+	}
+	if len(topology.siblings) == 0 {
+		topology.siblings = []int{cpu}
+	}
+	sort.Ints(topology.siblings)
+	// the cpu is linked to its node, there is no link if the kernel is built without numa
+	nodes, err := filepath.Glob(filepath.Join(cpuPath, "node*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if index, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(node), "node")); err == nil {
+			topology.node = index
+			break
+		}
+	}
+	return topology, nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveCpuList(t *testing.T) {
+	cpuSysPath = t.TempDir()
+	defer func() { cpuSysPath = "/sys/devices/system/cpu" }()
+	// two sockets of two cores with two threads, the siblings of cpu N are N and N+4, the socket is the numa node
+	for cpu := 0; cpu < 8; cpu++ {
+		socket := (cpu % 4) / 2
+		files := map[string]string{
+			"topology/physical_package_id": fmt.Sprint(socket),
+			"topology/core_cpus_list":      fmt.Sprintf("%d,%d", cpu%4, cpu%4+4),
+		}
+		for file, content := range files {
+			path := filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", cpu), file)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("create %s failed, %v", path, err)
+			}
+			if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+				t.Fatalf("write %s failed, %v", path, err)
+			}
+		}
+		if err := os.Mkdir(filepath.Join(cpuSysPath, fmt.Sprintf("cpu%d", cpu), fmt.Sprintf("node%d", socket)), 0o755); err != nil {
+			t.Fatalf("create node of cpu%d failed, %v", cpu, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(cpuSysPath, "online"), []byte("0-6\n"), 0o644); err != nil {
+		t.Fatalf("write online file failed, %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		expect  string
+		invalid bool
+	}{
+		{"no selector", map[string]string{"cpu-list": "1-2"}, "1-2", false},
+		{"numa node", map[string]string{"numa-node": "1"}, "2,3,6", false},
+		{"socket narrows cpu-list", map[string]string{"socket": "0", "cpu-list": "0-3"}, "0,1", false},
+		{"physical cores", map[string]string{"physical-cores-only": "true"}, "0,1,2,3", false},
+		{"physical cores of offline sibling", map[string]string{"physical-cores-only": "true", "cpu-list": "4-7"}, "4,5,6", false},
+		{"smt siblings", map[string]string{"smt-siblings-of": "0,2"}, "4,6", false},
+		{"offline smt sibling", map[string]string{"smt-siblings-of": "3"}, "", true},
+		{"illegal selector", map[string]string{"socket": "a"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resp := resolveCpuList(context.Background(), tt.flags)
+			if tt.invalid {
+				if resp == nil {
+					t.Errorf("expected %v to be refused, got %s", tt.flags, got)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("unexpected response: %s", resp.Err)
+			}
+			if got != tt.expect {
+				t.Errorf("unexpected cpu-list: %s, expected: %s", got, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+const (
+	WorkloadSpin       = "spin"
+	WorkloadInteger    = "integer"
+	WorkloadFloat      = "floating-point"
+	WorkloadCache      = "cache-thrash"
+	WorkloadBranchMiss = "branch-misprediction"
+)
+
+const (
+	// cacheLineSize is the size of a node of the cache-thrash buffer, so every step touches a new line
+	cacheLineSize = 64
+	// defaultThrashSize is used if the size of the last level cache is unknown
+	defaultThrashSize = 64 << 20
+	// workloadBatch is the number of iterations between two checks of the busy time
+	workloadBatch = 1024
+)
+
+// sink keeps the results of the workloads, so that the compiler can not drop the computation
+var sink uint64
+
+// workload runs the instructions of the burner until the busy time is up, it is shared by all of the burner threads
+type workload interface {
+	Run(busy time.Duration)
+}
+
+// parseWorkload parses the workload flag, the empty loop is used by default
+func parseWorkload(ctx context.Context, name string) (workload, *spec.Response) {
+	switch name {
+	case "", WorkloadSpin:
+		return spinWorkload{}, nil
+	case WorkloadInteger:
+		return integerWorkload{}, nil
+	case WorkloadFloat:
+		return floatWorkload{}, nil
+	case WorkloadBranchMiss:
+		return branchMissWorkload{}, nil
+	case WorkloadCache:
+		size := lastLevelCacheSize() * 2
+		if size <= 0 {
+			size = defaultThrashSize
+		}
+		log.Infof(ctx, "cache-thrash buffer size: %d bytes", size)
+		return newCacheThrashWorkload(size), nil
+	}
+	log.Errorf(ctx, "`%s`: workload is illegal", name)
+	return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "workload", name,
+		"it must be spin, integer, floating-point, cache-thrash or branch-misprediction")
+}
+
+// spinWorkload is the empty loop, it mostly waits on the clock
+type spinWorkload struct{}
+
+func (spinWorkload) Run(busy time.Duration) {
+	startTime := time.Now()
+	for time.Since(startTime) < busy {
+	}
+}
+
+// integerWorkload keeps the integer multipliers and shifters busy with a xorshift-multiply chain
+type integerWorkload struct{}
+
+func (integerWorkload) Run(busy time.Duration) {
+	x := uint64(time.Now().UnixNano()) | 1
+	for startTime := time.Now(); time.Since(startTime) < busy; {
+		for i := 0; i < workloadBatch; i++ {
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+			x *= 0x2545F4914F6CDD1D
+		}
+	}
+	atomic.AddUint64(&sink, x)
+}
+
+// floatWorkload keeps the floating-point units busy with multiply-add, division and square root
+type floatWorkload struct{}
+
+func (floatWorkload) Run(busy time.Duration) {
+	x, y := 1.0, 0.5
+	for startTime := time.Now(); time.Since(startTime) < busy; {
+		for i := 0; i < workloadBatch; i++ {
+			x = x*1.0000001 + y
+			y = math.Sqrt(x) / (y + 1.5)
+			if x > 1e12 {
+				x = 1.0
+			}
+		}
+	}
+	atomic.AddUint64(&sink, math.Float64bits(x+y))
+}
+
+// branchMissWorkload takes branches on random bits, so that the branch predictor is wrong half of the time
+type branchMissWorkload struct{}
+
+func (branchMissWorkload) Run(busy time.Duration) {
+	x := uint64(time.Now().UnixNano()) | 1
+	var a, b, c, d uint64
+	for startTime := time.Now(); time.Since(startTime) < busy; {
+		for i := 0; i < workloadBatch; i++ {
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+			// the cases differ so much that they can not be turned into conditional moves
+			switch x & 3 {
+			case 0:
+				a += x >> 3
+			case 1:
+				b ^= x << 5
+			case 2:
+				c = c*31 + x
+			default:
+				d -= x >> 11
+			}
+		}
+	}
+	atomic.AddUint64(&sink, a+b+c+d)
+}
+
+// cacheThrashWorkload chases pointers through a random cycle of cache lines in a buffer larger
+// than the last level cache, so that nearly every step misses the caches and the prefetcher.
+type cacheThrashWorkload struct {
+	lines []cacheLine
+}
+
+type cacheLine struct {
+	next uint32
+	_    [cacheLineSize - 4]byte
+}
+
+func newCacheThrashWorkload(size int64) *cacheThrashWorkload {
+	lines := make([]cacheLine, size/cacheLineSize)
+	// linking the lines in a random order makes a single cycle through all of them
+	order := rand.Perm(len(lines))
+	for i := range order {
+		lines[order[i]].next = uint32(order[(i+1)%len(order)])
+	}
+	return &cacheThrashWorkload{lines: lines}
+}
+
+func (w *cacheThrashWorkload) Run(busy time.Duration) {
+	next := uint32(rand.Intn(len(w.lines)))
+	for startTime := time.Now(); time.Since(startTime) < busy; {
+		for i := 0; i < workloadBatch; i++ {
+			next = w.lines[next].next
+		}
+	}
+	atomic.AddUint64(&sink, uint64(next))
+}
+
+// lastLevelCacheSize returns the size in bytes of the largest cache of cpu0, or 0 if it is unknown
+func lastLevelCacheSize() int64 {
+	sizes, err := filepath.Glob(filepath.Join(cpuSysPath, "cpu0", "cache", "index*", "size"))
+	if err != nil {
+		return 0
+	}
+	var largest int64
+	for _, file := range sizes {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if size := parseCacheSize(strings.TrimSpace(string(content))); size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+// parseCacheSize parses the size of sysfs cache, for example 32K or 16M
+func parseCacheSize(size string) int64 {
+	unit := int64(1)
+	switch {
+	case strings.HasSuffix(size, "K"):
+		unit, size = 1<<10, strings.TrimSuffix(size, "K")
+	case strings.HasSuffix(size, "M"):
+		unit, size = 1<<20, strings.TrimSuffix(size, "M")
+	}
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value * unit
+}
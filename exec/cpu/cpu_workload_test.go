@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cpu
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseCacheSize(t *testing.T) {
+	tests := map[string]int64{"32K": 32 << 10, "16M": 16 << 20, "512": 512, "": 0, "1G": 0}
+	for size, expect := range tests {
+		if got := parseCacheSize(size); got != expect {
+			t.Errorf("unexpected size of %s: %d, expected: %d", size, got, expect)
+		}
+	}
+}
+
+func TestCacheThrashSingleCycle(t *testing.T) {
+	w := newCacheThrashWorkload(1024 * cacheLineSize)
+	visited := make(map[uint32]bool, len(w.lines))
+	next := uint32(0)
+	for range w.lines {
+		if visited[next] {
+			t.Fatalf("line %d is visited twice before all of the lines", next)
+		}
+		visited[next] = true
+		next = w.lines[next].next
+	}
+	if next != 0 {
+		t.Errorf("the walk does not return to the first line")
+	}
+}
+
+func TestWorkloadRun(t *testing.T) {
+	for _, name := range []string{"", WorkloadSpin, WorkloadInteger, WorkloadFloat, WorkloadBranchMiss} {
+		w, resp := parseWorkload(context.Background(), name)
+		if resp != nil {
+			t.Fatalf("unexpected response of %s: %s", name, resp.Err)
+		}
+		startTime := time.Now()
+		w.Run(10 * time.Millisecond)
+		if elapsed := time.Since(startTime); elapsed < 10*time.Millisecond || elapsed > time.Second {
+			t.Errorf("%s runs for %s, expected 10ms", name, elapsed)
+		}
+	}
+	if _, resp := parseWorkload(context.Background(), "idle"); resp == nil {
+		t.Errorf("expected idle to be illegal")
+	}
+}
@@ -19,7 +19,10 @@ package disk
 import (
 	"context"
 	"fmt"
+	"math"
+	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/channel"
@@ -66,7 +69,39 @@ func NewBurnActionSpec() spec.ExpActionCommandSpec {
 				},
 				&spec.ExpFlag{
 					Name: "path",
-					Desc: "The path of directory where the disk is burning, default value is /",
+					Desc: "The path of directory where the disk is burning, default value is /. It is in the mount namespace of the target under the nsexec channel",
+				},
+				&spec.ExpFlag{
+					Name: "block-size",
+					Desc: "Block size of an io with unit K or M, for example 4K, it must be a multiple of 512 bytes. It overrides the size flag",
+				},
+				&spec.ExpFlag{
+					Name: "file-size",
+					Desc: "Size of the files to read and write, unit is MB, default value is 600 for read and 100 blocks for write",
+				},
+				&spec.ExpFlag{
+					Name: "pattern",
+					Desc: "Access pattern of the io, seq or rand, default value is seq",
+				},
+				&spec.ExpFlag{
+					Name: "direct",
+					Desc: "Bypass the page cache of the reads and writes by O_DIRECT, true or false. The writes are synced by O_DSYNC without it. By default the reads are direct and the writes are synced as dd did, and the reads fall back to the page cache if the filesystem does not support O_DIRECT",
+				},
+				&spec.ExpFlag{
+					Name: "queue-depth",
+					Desc: "Number of the concurrent io workers, default value is 1",
+				},
+				&spec.ExpFlag{
+					Name: "read-percent",
+					Desc: "Percent of the reads (0-100) if both read and write flags exist, default value is 50",
+				},
+				&spec.ExpFlag{
+					Name: "bps",
+					Desc: "Target throughput of all the workers, unit is MB/s, default value is unlimited",
+				},
+				&spec.ExpFlag{
+					Name: "iops",
+					Desc: "Target iops of all the workers, default value is unlimited. The lower one wins if both bps and iops flags exist",
 				},
 			},
 			ActionExecutor: &BurnIOExecutor{},
@@ -78,7 +113,13 @@ blade create disk burn --read --path /home
 blade create disk burn --write --path /home
 
 # Read and write IO load scenarios are performed at the same time. Path is not specified. The default is /
-blade create disk burn --read --write`,
+blade create disk burn --read --write
+
+# 4k random writes at 2000 iops
+blade create disk burn --write --path /home --block-size 4K --pattern rand --iops 2000
+
+# 70% reads of 64k by 8 concurrent workers at 200MB/s
+blade create disk burn --read --write --path /home --block-size 64K --queue-depth 8 --read-percent 70 --bps 200`,
 			ActionPrograms:    []string{BurnIOBin},
 			ActionCategories:  []string{category.SystemDisk},
 			ActionProcessHang: true,
@@ -102,7 +143,9 @@ func (b *BurnActionSpec) LongDesc() string {
 	if b.ActionLongDesc != "" {
 		return b.ActionLongDesc
 	}
-	return "Increase disk read and write io load"
+	return "Increase disk read and write io load by the io workers of the burner, the block size, the access pattern, " +
+		"the queue depth, the read and write mix and the target throughput or iops are configurable. " +
+		"The throughput and iops are reported to the log every 5 seconds"
 }
 
 type BurnIOExecutor struct {
@@ -113,13 +156,41 @@ func (*BurnIOExecutor) Name() string {
 	return "burn"
 }
 
-var localChannel = channel.NewLocalChannel()
+// burnSpec holds the parsed flags of disk burn, bps is in bytes per second and bps and iops are
+// 0 if they are not throttled
+type burnSpec struct {
+	read          bool
+	write         bool
+	readPercent   int
+	blockSize     int64
+	readFileSize  int64
+	writeFileSize int64
+	random        bool
+	readDirect    bool
+	writeDirect   bool
+	// directFallback opens the file for reading without O_DIRECT if the filesystem refuses it
+	directFallback bool
+	queueDepth     int
+	bps            float64
+	iops           int
+}
+
+const (
+	PatternSeq  = "seq"
+	PatternRand = "rand"
+)
+
+const (
+	// defaultReadFileSize is the size of the file for reading, in MB
+	defaultReadFileSize = 600
+	// defaultWriteBlocks is the blocks of the file for writing
+	defaultWriteBlocks = 100
+)
 
 func (be *BurnIOExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
-	commands := []string{"rm", "dd"}
-	// use local channel
-	if response, ok := localChannel.IsAllCommandsAvailable(ctx, commands); !ok {
-		return response
+	if be.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
 	}
 	directory := model.ActionFlags["path"]
 	if directory == "" {
@@ -133,7 +204,12 @@ func (be *BurnIOExecutor) Exec(uid string, ctx context.Context, model *spec.ExpM
 			readExists = true
 			writeExists = true
 		}
-		return be.stop(ctx, readExists, writeExists, directory)
+		return be.stop(ctx, readExists, writeExists, directory, model.ActionFlags)
+	}
+	directory, err := namespacePath(be.channel, model.ActionFlags, directory)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: path is invalid in the mount namespace of the target, %v", model.ActionFlags["path"], err)
+		return spec.ResponseFailWithFlags(spec.ParameterInvalid, "path", model.ActionFlags["path"], err)
 	}
 	if !util.IsDir(directory) {
 		log.Errorf(ctx, "`%s`: path is illegal, is not a directory", directory)
@@ -145,38 +221,210 @@ func (be *BurnIOExecutor) Exec(uid string, ctx context.Context, model *spec.ExpM
 		log.Errorf(ctx, "less params, read|write")
 		return spec.ResponseFailWithFlags(spec.ParameterLess, "read|write")
 	}
-	size := model.ActionFlags["size"]
+	burn, resp := parseBurnSpec(ctx, model.ActionFlags, readExists, writeExists)
+	if resp != nil {
+		return resp
+	}
+	return be.start(ctx, directory, burn)
+}
+
+// parseBurnSpec parses the flags of the io workers, the block size is the size flag in MB unless
+// the block-size flag exists
+func parseBurnSpec(ctx context.Context, flags map[string]string, read, write bool) (*burnSpec, *spec.Response) {
+	burn := &burnSpec{read: read, write: write, readPercent: 50, queueDepth: 1}
+	switch direct := flags["direct"]; direct {
+	case "":
+		burn.readDirect, burn.directFallback = true, true
+	case "true":
+		burn.readDirect, burn.writeDirect = true, true
+	case "false":
+	default:
+		log.Errorf(ctx, "`%s`: direct is illegal, it must be true or false", direct)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "direct", direct, "it must be true or false")
+	}
+	if !write {
+		burn.readPercent = 100
+	} else if !read {
+		burn.readPercent = 0
+	}
+	size := flags["size"]
 	if size == "" {
 		size = "10"
 	}
-	return be.start(ctx, readExists, writeExists, directory, size)
+	sizeMB, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || sizeMB <= 0 {
+		log.Errorf(ctx, "`%s`: size is illegal, it must be a positive integer", size)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "size", size, "it must be a positive integer")
+	}
+	burn.blockSize = sizeMB << 20
+	if blockSizeStr := flags["block-size"]; blockSizeStr != "" {
+		burn.blockSize = parseBlockSize(blockSizeStr)
+		if burn.blockSize <= 0 {
+			log.Errorf(ctx, "`%s`: block-size is illegal, it must be a multiple of 512 bytes with unit K or M", blockSizeStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "block-size", blockSizeStr, "it must be a multiple of 512 bytes with unit K or M")
+		}
+	}
+	burn.readFileSize = defaultReadFileSize << 20
+	burn.writeFileSize = defaultWriteBlocks * burn.blockSize
+	if fileSizeStr := flags["file-size"]; fileSizeStr != "" {
+		fileSize, err := strconv.ParseInt(fileSizeStr, 10, 64)
+		if err != nil || fileSize <= 0 {
+			log.Errorf(ctx, "`%s`: file-size is illegal, it must be a positive integer", fileSizeStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "file-size", fileSizeStr, "it must be a positive integer")
+		}
+		burn.readFileSize = fileSize << 20
+		burn.writeFileSize = fileSize << 20
+	}
+	// the offsets are aligned to the block size, so the files hold whole blocks
+	burn.readFileSize = max(burn.readFileSize/burn.blockSize, 1) * burn.blockSize
+	burn.writeFileSize = max(burn.writeFileSize/burn.blockSize, 1) * burn.blockSize
+	switch pattern := flags["pattern"]; pattern {
+	case "", PatternSeq:
+	case PatternRand:
+		burn.random = true
+	default:
+		log.Errorf(ctx, "`%s`: pattern is illegal, it must be seq or rand", pattern)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "pattern", pattern, "it must be seq or rand")
+	}
+	if queueDepthStr := flags["queue-depth"]; queueDepthStr != "" {
+		queueDepth, err := strconv.Atoi(queueDepthStr)
+		if err != nil || queueDepth <= 0 {
+			log.Errorf(ctx, "`%s`: queue-depth is illegal, it must be a positive integer", queueDepthStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "queue-depth", queueDepthStr, "it must be a positive integer")
+		}
+		burn.queueDepth = queueDepth
+	}
+	if readPercentStr := flags["read-percent"]; readPercentStr != "" {
+		if !read || !write {
+			log.Errorf(ctx, "`%s`: read-percent needs both read and write flags", readPercentStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "read-percent", readPercentStr, "it needs both read and write flags")
+		}
+		readPercent, err := strconv.Atoi(readPercentStr)
+		if err != nil || readPercent < 0 || readPercent > 100 {
+			log.Errorf(ctx, "`%s`: read-percent is illegal, it must be an integer in [0,100]", readPercentStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "read-percent", readPercentStr, "it must be an integer in [0,100]")
+		}
+		burn.readPercent = readPercent
+	}
+	if bpsStr := flags["bps"]; bpsStr != "" {
+		bps, err := strconv.ParseFloat(bpsStr, 64)
+		if err != nil || bps <= 0 || math.IsInf(bps, 0) {
+			log.Errorf(ctx, "`%s`: bps is illegal, it must be a positive number", bpsStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "bps", bpsStr, "it must be a positive number")
+		}
+		burn.bps = bps * (1 << 20)
+	}
+	if iopsStr := flags["iops"]; iopsStr != "" {
+		iops, err := strconv.Atoi(iopsStr)
+		if err != nil || iops <= 0 {
+			log.Errorf(ctx, "`%s`: iops is illegal, it must be a positive integer", iopsStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "iops", iopsStr, "it must be a positive integer")
+		}
+		burn.iops = iops
+	}
+	return burn, nil
 }
 
-func (be *BurnIOExecutor) start(ctx context.Context, read, write bool, directory, size string) *spec.Response {
-	if read {
-		go burnRead(ctx, directory, size, be.channel)
+// parseBlockSize parses the size with unit K or M, or in bytes without unit, it returns 0 if the
+// size is illegal or not a multiple of 512
+func parseBlockSize(size string) int64 {
+	unit := int64(1)
+	switch strings.ToUpper(size[len(size)-1:]) {
+	case "K":
+		unit = 1 << 10
+		size = size[:len(size)-1]
+	case "M":
+		unit = 1 << 20
+		size = size[:len(size)-1]
 	}
-	if write {
-		go burnWrite(ctx, directory, size, be.channel)
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value <= 0 || value*unit%512 != 0 {
+		return 0
+	}
+	return value * unit
+}
+
+// namespacePath returns the path in the mount namespace of the target under the nsexec channel,
+// by the root of the target in /proc, so that the burn and the throttle on the host work on the
+// volumes of the container.
+func namespacePath(cl spec.Channel, flags map[string]string, p string) (string, error) {
+	if _, ok := cl.(*channel.NSExecChannel); !ok || flags[channel.NSMntFlagName] != spec.True {
+		return p, nil
+	}
+	pid, err := strconv.Atoi(flags[channel.NSTargetFlagName])
+	if err != nil || pid <= 0 {
+		return "", fmt.Errorf("the target pid `%s` is illegal", flags[channel.NSTargetFlagName])
+	}
+	root := fmt.Sprintf("/proc/%d/root", pid)
+	if _, err := os.Stat(root); err != nil {
+		return "", fmt.Errorf("the root of the target %d is not accessible, %v", pid, err)
+	}
+	return joinInRoot(root, p)
+}
+
+// maxSymlinks is the number of the symlinks followed by joinInRoot, the same as the kernel
+const maxSymlinks = 40
+
+// joinInRoot joins the path to the root, resolving the symlinks of its components within the
+// root, so that neither `..` nor the symlinks of the container escape to the host. The absolute
+// symlinks are relative to the root, as they are in the container.
+func joinInRoot(root, p string) (string, error) {
+	resolved := "/"
+	components := strings.Split(p, "/")
+	for links := 0; len(components) > 0; {
+		component := components[0]
+		components = components[1:]
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			// the parent of / is / itself
+			resolved = path.Dir(resolved)
+			continue
+		}
+		next := path.Join(resolved, component)
+		target, err := os.Readlink(path.Join(root, next))
+		if err != nil {
+			// not a symlink, or not existing yet
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("too many levels of symbolic links in %s", p)
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		components = append(strings.Split(target, "/"), components...)
 	}
-	select {}
+	return path.Join(root, resolved), nil
 }
 
-func (be *BurnIOExecutor) stop(ctx context.Context, read, write bool, directory string) *spec.Response {
+func (be *BurnIOExecutor) start(ctx context.Context, directory string, burn *burnSpec) *spec.Response {
+	return burnIO(ctx, directory, burn)
+}
+
+// stop kills the burner and then removes its files. The files are cleaned up best-effort, the
+// root of the target is gone if it has exited, the burner is killed anyway.
+func (be *BurnIOExecutor) stop(ctx context.Context, read, write bool, directory string, flags map[string]string) *spec.Response {
+	ctx = context.WithValue(ctx, "bin", BurnIOBin)
+	response := exec.Destroy(ctx, be.channel, "disk burn")
+	directory, err := namespacePath(be.channel, flags, directory)
+	if err != nil {
+		log.Warnf(ctx, "`%s`: the files of disk burn are not cleaned, the path is invalid in the mount namespace of the target, %v", flags["path"], err)
+		return response
+	}
 	if read {
-		resp := localChannel.Run(ctx, "rm", fmt.Sprintf("-rf %s*", path.Join(directory, readFile)))
-		if !resp.Success {
-			log.Errorf(ctx, "clean read file: %s", resp.Err)
+		if err := os.Remove(path.Join(directory, readFile)); err != nil && !os.IsNotExist(err) {
+			log.Errorf(ctx, "clean read file: %v", err)
 		}
 	}
 	if write {
-		resp := localChannel.Run(ctx, "rm", fmt.Sprintf("-rf %s*", path.Join(directory, writeFile)))
-		if !resp.Success {
-			log.Errorf(ctx, "clean write file: %s", resp.Err)
+		if err := os.Remove(path.Join(directory, writeFile)); err != nil && !os.IsNotExist(err) {
+			log.Errorf(ctx, "clean write file: %v", err)
 		}
 	}
-	ctx = context.WithValue(ctx, "bin", BurnIOBin)
-	return exec.Destroy(ctx, be.channel, "disk burn")
+	return response
 }
 
 func (be *BurnIOExecutor) SetChannel(channel spec.Channel) {
@@ -187,59 +435,3 @@ var (
 	readFile  = "chaos_burnio.read"
 	writeFile = "chaos_burnio.write"
 )
-
-const count = 100
-
-// write burn
-func burnWrite(ctx context.Context, directory, size string, cl spec.Channel) {
-	tmpFileForWrite := path.Join(directory, writeFile)
-	_, _, ddRunningWriteArg := getArgs(ctx, localChannel)
-	for {
-		args := fmt.Sprintf(ddRunningWriteArg, tmpFileForWrite, size, count)
-		response := localChannel.Run(ctx, "dd", args)
-		if !response.Success {
-			log.Errorf(ctx, "disk burn write, run dd err: %s", response.Err)
-			break
-		}
-	}
-}
-
-// read burn
-func burnRead(ctx context.Context, directory, size string, cl spec.Channel) {
-	// create a 600M file under the directory
-	tmpFileForRead := path.Join(directory, readFile)
-	ddCreateArg, ddRunningReadArg, _ := getArgs(ctx, localChannel)
-	createArgs := fmt.Sprintf(ddCreateArg, tmpFileForRead, 6, count)
-	response := localChannel.Run(ctx, "dd", createArgs)
-	if !response.Success {
-		log.Errorf(ctx, "disk burn read, run dd err: %s", response.Err)
-	}
-
-	for {
-		args := fmt.Sprintf(ddRunningReadArg, tmpFileForRead, size, count)
-		// run with local channel
-		response := localChannel.Run(ctx, "dd", args)
-		if !response.Success {
-			log.Errorf(ctx, "disk burn read, run dd err: %s", response.Err)
-			break
-		}
-	}
-}
-
-func getArgs(ctx context.Context, cl spec.Channel) (string, string, string) {
-	createArgs := "if=/dev/zero of=%s bs=%dM count=%d oflag=dsync"
-	runningReadArgs := "if=%s of=/dev/null bs=%sM count=%d iflag=dsync,direct,fullblock"
-	runningWriteArgs := "if=/dev/zero of=%s bs=%sM count=%d oflag=dsync"
-	response := cl.Run(ctx, "cat", "/etc/os-release")
-	if !response.Success {
-		log.Warnf(ctx, "cat /etc/os-release failed, %v. use the default value.", response.Err)
-		return createArgs, runningReadArgs, runningWriteArgs
-	}
-	if response.Result != nil && strings.Contains(strings.ToUpper(response.Result.(string)), "ID=ALPINE") {
-		// alpine linux
-		createArgs = "if=/dev/zero of=%s bs=%dM count=%d oflag=append"
-		runningReadArgs = "if=%s of=/dev/null bs=%sM count=%d iflag=fullblock oflag=append"
-		runningWriteArgs = "if=/dev/zero of=%s bs=%sM count=%d oflag=append"
-	}
-	return createArgs, runningReadArgs, runningWriteArgs
-}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/channel"
+)
+
+func TestParseBlockSize(t *testing.T) {
+	tests := map[string]int64{
+		"4K":   4 << 10,
+		"4k":   4 << 10,
+		"1M":   1 << 20,
+		"512":  512,
+		"100":  0,
+		"K":    0,
+		"-4K":  0,
+		"1.5M": 0,
+	}
+	for size, expect := range tests {
+		if got := parseBlockSize(size); got != expect {
+			t.Errorf("unexpected size of %s: %d, expected: %d", size, got, expect)
+		}
+	}
+}
+
+func TestParseBurnSpec(t *testing.T) {
+	burn, resp := parseBurnSpec(context.Background(), map[string]string{"block-size": "4K", "file-size": "1", "pattern": "rand"}, true, true)
+	if resp != nil {
+		t.Fatalf("parse burn spec failed, %s", resp.Err)
+	}
+	if burn.blockSize != 4<<10 || burn.readFileSize != 1<<20 || !burn.random || !burn.readDirect || burn.writeDirect || !burn.directFallback || burn.readPercent != 50 {
+		t.Errorf("unexpected burn spec: %+v", burn)
+	}
+	burn, resp = parseBurnSpec(context.Background(), map[string]string{"size": "3", "direct": "false"}, false, true)
+	if resp != nil {
+		t.Fatalf("parse burn spec failed, %s", resp.Err)
+	}
+	if burn.blockSize != 3<<20 || burn.writeFileSize != 300<<20 || burn.readDirect || burn.writeDirect || burn.readPercent != 0 {
+		t.Errorf("unexpected burn spec: %+v", burn)
+	}
+	for _, flags := range []map[string]string{
+		{"pattern": "zigzag"},
+		{"queue-depth": "0"},
+		{"read-percent": "50"},
+		{"iops": "-1"},
+		{"direct": "yes"},
+	} {
+		if _, resp := parseBurnSpec(context.Background(), flags, true, false); resp == nil {
+			t.Errorf("expected error of the flags %v", flags)
+		}
+	}
+}
+
+func TestNamespacePath(t *testing.T) {
+	flags := map[string]string{channel.NSTargetFlagName: strconv.Itoa(os.Getpid()), channel.NSMntFlagName: "true"}
+	if directory, err := namespacePath(channel.NewLocalChannel(), flags, "/data"); err != nil || directory != "/data" {
+		t.Errorf("unexpected directory of the local channel: %s, %v", directory, err)
+	}
+	expect := fmt.Sprintf("/proc/%d/root/data", os.Getpid())
+	if directory, err := namespacePath(channel.NewNSExecChannel(), flags, "/data"); err != nil || directory != expect {
+		t.Errorf("unexpected directory of the nsexec channel: %s, %v, expected: %s", directory, err, expect)
+	}
+	// the traversal stops at the root of the target
+	expect = fmt.Sprintf("/proc/%d/root/etc", os.Getpid())
+	if directory, err := namespacePath(channel.NewNSExecChannel(), flags, "../../../etc"); err != nil || directory != expect {
+		t.Errorf("unexpected directory of the traversal: %s, %v, expected: %s", directory, err, expect)
+	}
+	flags[channel.NSTargetFlagName] = "0"
+	if _, err := namespacePath(channel.NewNSExecChannel(), flags, "/data"); err == nil {
+		t.Errorf("expected error of the illegal target")
+	}
+}
+
+func TestJoinInRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mnt", "vol"), 0o755); err != nil {
+		t.Fatalf("create the volume failed, %v", err)
+	}
+	for link, target := range map[string]string{
+		"data":   "/mnt/vol",
+		"up":     "../../../..",
+		"loop":   "loop",
+		"rel":    "mnt/vol",
+		"escape": "/../../etc",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatalf("link %s failed, %v", link, err)
+		}
+	}
+	tests := map[string]string{
+		"/data":          "/mnt/vol",
+		"/data/sub":      "/mnt/vol/sub",
+		"../../../data":  "/mnt/vol",
+		"/up/etc":        "/etc",
+		"/rel":           "/mnt/vol",
+		"/escape":        "/etc",
+		"/mnt/vol/../..": "/",
+	}
+	for p, expect := range tests {
+		if got, err := joinInRoot(root, p); err != nil || got != filepath.Join(root, expect) {
+			t.Errorf("unexpected path of %s: %s, %v, expected: %s", p, got, err, filepath.Join(root, expect))
+		}
+	}
+	if _, err := joinInRoot(root, "/loop"); err == nil {
+		t.Errorf("expected error of the symlink loop")
+	}
+}
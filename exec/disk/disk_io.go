@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"golang.org/x/sys/unix"
+)
+
+// burnReportInterval is the interval of reporting the throughput and iops to the log
+const burnReportInterval = 5 * time.Second
+
+// prepareChunk is the size of a write when the file for reading is created
+const prepareChunk = 1 << 20
+
+// ioTarget is a file of the burner, its offsets are aligned to the block size. The cursor is
+// shared by the workers, so that the sequential io of all of them is a single stream.
+type ioTarget struct {
+	file   *os.File
+	blocks int64
+	cursor atomic.Int64
+}
+
+func (t *ioTarget) offset(random bool, rng *rand.Rand, blockSize int64) int64 {
+	if random {
+		return rng.Int64N(t.blocks) * blockSize
+	}
+	return (t.cursor.Add(1) - 1) % t.blocks * blockSize
+}
+
+// pacer spaces the io of all the workers by the interval, a worker waits for its slot before
+// it submits an io. The interval is 0 if it is not throttled.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newPacer(burn *burnSpec) *pacer {
+	var interval time.Duration
+	if burn.iops > 0 {
+		interval = time.Second / time.Duration(burn.iops)
+	}
+	if burn.bps > 0 {
+		interval = max(interval, time.Duration(float64(burn.blockSize)/burn.bps*float64(time.Second)))
+	}
+	return &pacer{interval: interval}
+}
+
+func (p *pacer) wait() {
+	if p.interval == 0 {
+		return
+	}
+	time.Sleep(time.Until(p.reserve(time.Now())))
+}
+
+// reserve takes the next slot at now. The slots missed by the oversleep and the slow io are
+// caught up within a second, the older ones are dropped instead of bursting the device.
+func (p *pacer) reserve(now time.Time) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next.Before(now.Add(-time.Second)) {
+		p.next = now
+	}
+	slot := p.next
+	p.next = p.next.Add(p.interval)
+	return slot
+}
+
+// ioStats is the bytes and io counts since the last report
+type ioStats struct {
+	readBytes  atomic.Int64
+	writeBytes atomic.Int64
+	ios        atomic.Int64
+}
+
+// burnIO opens the files of the burn and runs the workers, the throughput and iops are reported
+// every 5 seconds until a worker fails.
+func burnIO(ctx context.Context, directory string, burn *burnSpec) *spec.Response {
+	var reader, writer *ioTarget
+	if burn.read {
+		name := path.Join(directory, readFile)
+		file, err := prepareFile(name, burn.readFileSize, true, burn.readDirect)
+		if err != nil && burn.readDirect && burn.directFallback && errors.Is(err, unix.EINVAL) {
+			log.Warnf(ctx, "disk burn read, O_DIRECT is not supported by the filesystem of %s, read by the page cache", name)
+			burn.readDirect = false
+			file, err = prepareFile(name, burn.readFileSize, true, false)
+		}
+		if err != nil {
+			log.Errorf(ctx, "disk burn read, prepare the file failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("disk burn read, prepare the file failed, %v", err))
+		}
+		defer file.Close()
+		reader = &ioTarget{file: file, blocks: burn.readFileSize / burn.blockSize}
+	}
+	if burn.write {
+		file, err := prepareFile(path.Join(directory, writeFile), burn.writeFileSize, false, burn.writeDirect)
+		if err != nil {
+			log.Errorf(ctx, "disk burn write, prepare the file failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("disk burn write, prepare the file failed, %v", err))
+		}
+		defer file.Close()
+		writer = &ioTarget{file: file, blocks: burn.writeFileSize / burn.blockSize}
+	}
+	log.Infof(ctx, "disk burn, block size: %d, random: %t, read direct: %t, write direct: %t, queue depth: %d, read percent: %d, bps: %f, iops: %d",
+		burn.blockSize, burn.random, burn.readDirect, burn.writeDirect, burn.queueDepth, burn.readPercent, burn.bps, burn.iops)
+
+	p := newPacer(burn)
+	var stats ioStats
+	failed := make(chan error, burn.queueDepth)
+	for i := 0; i < burn.queueDepth; i++ {
+		go func() {
+			failed <- runWorker(ctx, burn, reader, writer, p, &stats)
+		}()
+	}
+	ticker := time.NewTicker(burnReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-failed:
+			log.Errorf(ctx, "disk burn, the io worker failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("disk burn, the io worker failed, %v", err))
+		case <-ticker.C:
+			seconds := burnReportInterval.Seconds()
+			log.Infof(ctx, "disk burn, read: %.2fMB/s, write: %.2fMB/s, iops: %.0f",
+				float64(stats.readBytes.Swap(0))/(1<<20)/seconds, float64(stats.writeBytes.Swap(0))/(1<<20)/seconds,
+				float64(stats.ios.Swap(0))/seconds)
+		}
+	}
+}
+
+// runWorker submits the io one by one until the ctx is done, a read or a write is chosen by the
+// read percent. The buffer is mapped, so that it is aligned to the page as O_DIRECT requires.
+func runWorker(ctx context.Context, burn *burnSpec, reader, writer *ioTarget, p *pacer, stats *ioStats) error {
+	buf, err := unix.Mmap(-1, 0, int(burn.blockSize), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANON)
+	if err != nil {
+		return fmt.Errorf("map the buffer failed, %v", err)
+	}
+	defer unix.Munmap(buf)
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	fillRandom(rng, buf)
+	for ctx.Err() == nil {
+		p.wait()
+		if rng.IntN(100) < burn.readPercent {
+			offset := reader.offset(burn.random, rng, burn.blockSize)
+			if _, err := reader.file.ReadAt(buf, offset); err != nil {
+				return fmt.Errorf("read %s at %d failed, %v", reader.file.Name(), offset, err)
+			}
+			stats.readBytes.Add(burn.blockSize)
+		} else {
+			offset := writer.offset(burn.random, rng, burn.blockSize)
+			if _, err := writer.file.WriteAt(buf, offset); err != nil {
+				return fmt.Errorf("write %s at %d failed, %v", writer.file.Name(), offset, err)
+			}
+			stats.writeBytes.Add(burn.blockSize)
+		}
+		stats.ios.Add(1)
+	}
+	return nil
+}
+
+// prepareFile creates the file of the size and opens it for the io. The file for reading is
+// filled with data, because the holes of a sparse file are read without io, and it is kept if
+// it is of the size already.
+func prepareFile(name string, size int64, fill, direct bool) (*os.File, error) {
+	// the directory may be in the container, a symlink of the name must not be followed to the host
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR|unix.O_NOFOLLOW, 0o644) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err == nil && info.Size() != size {
+		if err = file.Truncate(0); err == nil && fill {
+			err = fillFile(file, size)
+		}
+		if err == nil {
+			err = file.Truncate(size)
+		}
+		if err == nil {
+			err = file.Sync()
+		}
+	}
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	flag := os.O_RDONLY | unix.O_NOFOLLOW
+	if !fill {
+		flag = os.O_WRONLY | unix.O_NOFOLLOW
+		if !direct {
+			flag |= unix.O_DSYNC
+		}
+	}
+	return openFile(name, flag, direct)
+}
+
+func fillFile(file *os.File, size int64) error {
+	chunk := make([]byte, prepareChunk)
+	fillRandom(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), chunk)
+	for written := int64(0); written < size; written += prepareChunk {
+		if _, err := file.Write(chunk[:min(prepareChunk, size-written)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillRandom fills the buffer with random data, so that it is neither compressed nor deduplicated
+// by the device
+func fillRandom(rng *rand.Rand, buf []byte) {
+	for i := 0; i+8 <= len(buf); i += 8 {
+		v := rng.Uint64()
+		for j := 0; j < 8; j++ {
+			buf[i+j] = byte(v >> (8 * j))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openFile sets F_NOCACHE if direct, there is no O_DIRECT on darwin
+func openFile(name string, flag int, direct bool) (*os.File, error) {
+	file, err := os.OpenFile(name, flag, 0o644) //nolint:gosec
+	if err != nil || !direct {
+		return file, err
+	}
+	if _, err := unix.FcntlInt(file.Fd(), unix.F_NOCACHE, 1); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// blockDevice is not supported on darwin, there is no cgroup to throttle the device
+func blockDevice(p string) (string, error) {
+	return "", errors.New("disk throttle is not supported on darwin")
+}
+
+// throttleCgroup is not supported on darwin, there is no cgroup
+func throttleCgroup(ctx context.Context, cgroupRoot, uid string, pid int, device string, limits *ioLimits) (string, error) {
+	return "", errors.New("disk throttle is not supported on darwin")
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openFile opens the file by O_DIRECT if direct, which bypasses the page cache
+func openFile(name string, flag int, direct bool) (*os.File, error) {
+	if direct {
+		flag |= unix.O_DIRECT
+	}
+	return os.OpenFile(name, flag, 0o644) //nolint:gosec
+}
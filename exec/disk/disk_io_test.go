@@ -0,0 +1,131 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runEngine runs the workers of the burn on the files in a temporary directory for the duration
+func runEngine(t *testing.T, burn *burnSpec, duration time.Duration) *ioStats {
+	directory := t.TempDir()
+	var reader, writer *ioTarget
+	if burn.read {
+		file, err := prepareFile(filepath.Join(directory, readFile), burn.readFileSize, true, false)
+		if err != nil {
+			t.Fatalf("prepare the file for reading failed, %v", err)
+		}
+		defer file.Close()
+		reader = &ioTarget{file: file, blocks: burn.readFileSize / burn.blockSize}
+	}
+	if burn.write {
+		file, err := prepareFile(filepath.Join(directory, writeFile), burn.writeFileSize, false, false)
+		if err != nil {
+			t.Fatalf("prepare the file for writing failed, %v", err)
+		}
+		defer file.Close()
+		writer = &ioTarget{file: file, blocks: burn.writeFileSize / burn.blockSize}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	p := newPacer(burn)
+	var stats ioStats
+	var wg sync.WaitGroup
+	for i := 0; i < burn.queueDepth; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runWorker(ctx, burn, reader, writer, p, &stats); err != nil {
+				t.Errorf("the io worker failed, %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return &stats
+}
+
+func TestNewPacer(t *testing.T) {
+	tests := []struct {
+		name     string
+		burn     *burnSpec
+		interval time.Duration
+	}{
+		{"not throttled", &burnSpec{blockSize: 4 << 10}, 0},
+		{"iops", &burnSpec{blockSize: 4 << 10, iops: 400}, 2500 * time.Microsecond},
+		{"bps", &burnSpec{blockSize: 64 << 10, bps: 2 << 20}, time.Second / 32},
+		{"the slower of iops and bps", &burnSpec{blockSize: 64 << 10, bps: 2 << 20, iops: 400}, time.Second / 32},
+		{"the slower of bps and iops", &burnSpec{blockSize: 4 << 10, bps: 2 << 20, iops: 100}, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if interval := newPacer(tt.burn).interval; interval != tt.interval {
+				t.Errorf("unexpected interval: %v, expected %v", interval, tt.interval)
+			}
+		})
+	}
+}
+
+func TestPacerReserve(t *testing.T) {
+	p := newPacer(&burnSpec{blockSize: 4 << 10, iops: 400})
+	start := time.Unix(1000, 0)
+	slots := 0
+	for slot := p.reserve(start); slot.Before(start.Add(time.Second)); slot = p.reserve(start) {
+		if expected := start.Add(time.Duration(slots) * p.interval); !slot.Equal(expected) {
+			t.Fatalf("unexpected slot %d: %v, expected %v", slots, slot, expected)
+		}
+		slots++
+	}
+	if slots != 400 {
+		t.Errorf("unexpected slots in a second: %d, expected 400", slots)
+	}
+
+	// the slots missed in the last second are caught up
+	next := start.Add(time.Second + p.interval)
+	if slot := p.reserve(start.Add(1500 * time.Millisecond)); !slot.Equal(next) {
+		t.Errorf("unexpected slot after a lag: %v, expected %v", slot, next)
+	}
+	// the older ones are dropped
+	now := start.Add(5 * time.Second)
+	if slot := p.reserve(now); !slot.Equal(now) {
+		t.Errorf("unexpected slot after a long lag: %v, expected %v", slot, now)
+	}
+	if slot := p.reserve(now); !slot.Equal(now.Add(p.interval)) {
+		t.Errorf("unexpected slot after the drop: %v, expected %v", slot, now.Add(p.interval))
+	}
+}
+
+func TestEngine(t *testing.T) {
+	burn := &burnSpec{
+		read: true, write: true, readPercent: 70, blockSize: 4 << 10,
+		readFileSize: 1 << 20, writeFileSize: 1 << 20, random: true, queueDepth: 4, iops: 400,
+	}
+	stats := runEngine(t, burn, 500*time.Millisecond)
+	ios := stats.ios.Load()
+	// the pacer allows about 200 ios in the duration, the bound is coarse for the slow filesystem
+	if ios == 0 || ios > 400 {
+		t.Errorf("unexpected ios in 500ms: %d, expected about 200", ios)
+	}
+	reads := stats.readBytes.Load() / burn.blockSize
+	writes := stats.writeBytes.Load() / burn.blockSize
+	if reads+writes != ios {
+		t.Errorf("unexpected reads %d and writes %d of %d ios", reads, writes, ios)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const ThrottleDiskBin = "chaos_throttledisk"
+
+type ThrottleActionSpec struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewThrottleActionSpec() spec.ExpActionCommandSpec {
+	return &ThrottleActionSpec{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "pid",
+					Desc: "The pid of the process whose cgroup is throttled, the pid is in the host pid namespace",
+				},
+				&spec.ExpFlag{
+					Name: "process",
+					Desc: "The name of the process whose cgroup is throttled, the first matched process is used",
+				},
+				&spec.ExpFlag{
+					Name:     "cgroup-root",
+					Desc:     "cgroup root path, default value /sys/fs/cgroup",
+					NoArgs:   false,
+					Required: false,
+					Default:  "/sys/fs/cgroup",
+				},
+			},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name:     "path",
+					Desc:     "A file, a directory or a block device node on the throttled disk, a partition is throttled by its disk. It is in the mount namespace of the target under the nsexec channel",
+					Required: true,
+				},
+				&spec.ExpFlag{
+					Name: "read-bps",
+					Desc: "Read throughput limit, unit is MB/s",
+				},
+				&spec.ExpFlag{
+					Name: "write-bps",
+					Desc: "Write throughput limit, unit is MB/s",
+				},
+				&spec.ExpFlag{
+					Name: "read-iops",
+					Desc: "Read iops limit",
+				},
+				&spec.ExpFlag{
+					Name: "write-iops",
+					Desc: "Write iops limit",
+				},
+			},
+			ActionExecutor: &ThrottleIOExecutor{},
+			ActionExample: `
+# Limit the writes of the cgroup of the process 1234 to the disk of /data to 1MB/s
+blade create disk throttle --pid 1234 --path /data --write-bps 1
+
+# Limit the reads and writes of the cgroup of the mysqld process to the disk of /var/lib/mysql to 100 iops
+blade create disk throttle --process mysqld --path /var/lib/mysql --read-iops 100 --write-iops 100`,
+			ActionPrograms:   []string{ThrottleDiskBin},
+			ActionCategories: []string{category.SystemDisk},
+		},
+	}
+}
+
+func (*ThrottleActionSpec) Name() string {
+	return "throttle"
+}
+
+func (*ThrottleActionSpec) Aliases() []string {
+	return []string{}
+}
+
+func (*ThrottleActionSpec) ShortDesc() string {
+	return "Throttle the disk io of a process cgroup"
+}
+
+func (t *ThrottleActionSpec) LongDesc() string {
+	if t.ActionLongDesc != "" {
+		return t.ActionLongDesc
+	}
+	return "Limit the read and write throughput and iops of the cgroup which the target process belongs to on the disk of the path, " +
+		"by io.max of cgroup v2 or blkio.throttle.* of cgroup v1, to make a slow disk. The buffered writes are not limited by cgroup v1 " +
+		"until they are written back by the process. The original limits are restored on destroy"
+}
+
+type ThrottleIOExecutor struct {
+	channel spec.Channel
+}
+
+func (*ThrottleIOExecutor) Name() string {
+	return "throttle"
+}
+
+func (te *ThrottleIOExecutor) SetChannel(channel spec.Channel) {
+	te.channel = channel
+}
+
+// ioLimits holds the limits of the throttle, a limit is 0 if it is not set
+type ioLimits struct {
+	readBps   int64
+	writeBps  int64
+	readIops  int64
+	writeIops int64
+}
+
+func (te *ThrottleIOExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if te.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		if err := exec.RestoreState(ctx, ThrottleDiskBin, uid); err != nil {
+			log.Errorf(ctx, "restore the io limits failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("restore the io limits failed, %v", err))
+		}
+		return spec.Success()
+	}
+
+	limits, resp := parseIOLimits(ctx, model.ActionFlags)
+	if resp != nil {
+		return resp
+	}
+	pathStr := model.ActionFlags["path"]
+	if pathStr == "" {
+		log.Errorf(ctx, "path is nil")
+		return spec.ResponseFailWithFlags(spec.ParameterLess, "path")
+	}
+	devicePath, err := namespacePath(te.channel, model.ActionFlags, pathStr)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: path is invalid in the mount namespace of the target, %v", pathStr, err)
+		return spec.ResponseFailWithFlags(spec.ParameterInvalid, "path", pathStr, err)
+	}
+	device, err := blockDevice(devicePath)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: path is invalid, %v", pathStr, err)
+		return spec.ResponseFailWithFlags(spec.ParameterInvalid, "path", pathStr, err)
+	}
+	pid, resp := exec.TargetPid(ctx, te.channel, model)
+	if resp != nil {
+		return resp
+	}
+	if exec.StateExists(ThrottleDiskBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, ThrottleDiskBin+"."+uid)
+	}
+	result, err := throttleCgroup(ctx, model.ActionFlags["cgroup-root"], uid, pid, device, limits)
+	if err != nil {
+		log.Errorf(ctx, "throttle the io of the cgroup of pid %d on device %s failed, %v", pid, device, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("throttle the io of the cgroup of pid %d on device %s failed, %v", pid, device, err))
+	}
+	return spec.ReturnSuccess(result)
+}
+
+// parseIOLimits parses the limit flags, at least one of them must exist
+func parseIOLimits(ctx context.Context, flags map[string]string) (*ioLimits, *spec.Response) {
+	limits := &ioLimits{}
+	for _, name := range []string{"read-bps", "write-bps"} {
+		value := flags[name]
+		if value == "" {
+			continue
+		}
+		bps, err := strconv.ParseFloat(value, 64)
+		// the bytes are truncated, so the limit must be at least a byte
+		if err != nil || bps*(1<<20) < 1 || math.IsInf(bps, 0) {
+			log.Errorf(ctx, "`%s`: %s is illegal, it must be a positive number", value, name)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, name, value, "it must be a positive number")
+		}
+		if name == "read-bps" {
+			limits.readBps = int64(bps * (1 << 20))
+		} else {
+			limits.writeBps = int64(bps * (1 << 20))
+		}
+	}
+	for _, name := range []string{"read-iops", "write-iops"} {
+		value := flags[name]
+		if value == "" {
+			continue
+		}
+		iops, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || iops <= 0 {
+			log.Errorf(ctx, "`%s`: %s is illegal, it must be a positive integer", value, name)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, name, value, "it must be a positive integer")
+		}
+		if name == "read-iops" {
+			limits.readIops = iops
+		} else {
+			limits.writeIops = iops
+		}
+	}
+	if *limits == (ioLimits{}) {
+		log.Errorf(ctx, "less params, read-bps|write-bps|read-iops|write-iops")
+		return nil, spec.ResponseFailWithFlags(spec.ParameterLess, "read-bps|write-bps|read-iops|write-iops")
+	}
+	return limits, nil
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/containerd/cgroups"
+	"golang.org/x/sys/unix"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	cgroupsv2 "github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+// sysDevBlock is the sysfs directory of the block devices by major:minor
+var sysDevBlock = "/sys/dev/block"
+
+// ioLimit is a limit of the throttle with its key of io.max and its file of blkio
+type ioLimit struct {
+	key   string
+	file  string
+	value int64
+}
+
+func (l *ioLimits) list() []ioLimit {
+	var list []ioLimit
+	for _, limit := range []ioLimit{
+		{"rbps", "blkio.throttle.read_bps_device", l.readBps},
+		{"wbps", "blkio.throttle.write_bps_device", l.writeBps},
+		{"riops", "blkio.throttle.read_iops_device", l.readIops},
+		{"wiops", "blkio.throttle.write_iops_device", l.writeIops},
+	} {
+		if limit.value > 0 {
+			list = append(list, limit)
+		}
+	}
+	return list
+}
+
+// blockDevice returns the major:minor of the disk of the path, the path is a file or a directory
+// on the disk or the block device node. The io of a partition is throttled by its disk, the
+// kernel does not accept a partition.
+func blockDevice(p string) (string, error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(p, &stat); err != nil {
+		return "", err
+	}
+	dev := stat.Dev
+	if stat.Mode&unix.S_IFMT == unix.S_IFBLK {
+		dev = stat.Rdev
+	}
+	device := fmt.Sprintf("%d:%d", unix.Major(dev), unix.Minor(dev))
+	sysPath, err := filepath.EvalSymlinks(filepath.Join(sysDevBlock, device))
+	if err != nil {
+		return "", fmt.Errorf("%s is not on a block device, its device is %s", p, device)
+	}
+	if _, err := os.Stat(filepath.Join(sysPath, "partition")); err != nil {
+		return device, nil
+	}
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(sysPath), "dev"))
+	if err != nil {
+		return "", fmt.Errorf("find the disk of partition %s failed, %v", device, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// throttleCgroup limits the io of the cgroup of the pid on the device, by io.max of cgroup v2 or
+// blkio.throttle.* of cgroup v1. The original values are saved to the state of uid.
+func throttleCgroup(ctx context.Context, cgroupRoot, uid string, pid int, device string, limits *ioLimits) (string, error) {
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	var original, values []exec.FileValue
+	if cgroupsv2.DetectCGroupVersion(ctx, cgroupRoot) == cgroupsv2.CGroupV2 {
+		cgroupPath, err := cgroupsv2.FindCGroupV2Path(ctx, strconv.Itoa(pid), cgroupRoot)
+		if err != nil {
+			return "", err
+		}
+		if cgroupPath == "" {
+			return "", fmt.Errorf("cgroup v2 path of pid %d not found", pid)
+		}
+		ioMax := filepath.Join(cgroupPath, "io.max")
+		content, err := os.ReadFile(ioMax)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("io controller is not enabled for cgroup %s", cgroupPath)
+			}
+			return "", err
+		}
+		current, line := parseIOMax(string(content), device)
+		value := device
+		for _, limit := range limits.list() {
+			if err := checkLower(ioMax, limit.key, current[limit.key], limit.value); err != nil {
+				return "", err
+			}
+			value += fmt.Sprintf(" %s=%d", limit.key, limit.value)
+		}
+		original = append(original, exec.FileValue{Path: ioMax, Value: line})
+		values = append(values, exec.FileValue{Path: ioMax, Value: value})
+	} else {
+		blkioPath, err := exec.PidPath(pid)(cgroups.Blkio)
+		if err != nil {
+			return "", err
+		}
+		cgroupPath := filepath.Join(cgroupRoot, string(cgroups.Blkio), blkioPath)
+		for _, limit := range limits.list() {
+			file := filepath.Join(cgroupPath, limit.file)
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return "", err
+			}
+			// no rule of the device is 0, which removes the rule when it is written back
+			current := "0"
+			for _, line := range strings.Split(string(content), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == device {
+					current = fields[1]
+				}
+			}
+			if current != "0" {
+				if err := checkLower(file, device, current, limit.value); err != nil {
+					return "", err
+				}
+			}
+			original = append(original, exec.FileValue{Path: file, Value: device + " " + current})
+			values = append(values, exec.FileValue{Path: file, Value: fmt.Sprintf("%s %d", device, limit.value)})
+		}
+	}
+
+	if err := exec.SaveState(ThrottleDiskBin, uid, original); err != nil {
+		return "", fmt.Errorf("save the original io limits failed, %v", err)
+	}
+	var result []string
+	for _, value := range values {
+		if err := os.WriteFile(value.Path, []byte(value.Value), 0o644); err != nil { //nolint:gosec
+			if restoreErr := exec.RestoreState(ctx, ThrottleDiskBin, uid); restoreErr != nil {
+				log.Warnf(ctx, "restore the io limits of %s failed, %v", uid, restoreErr)
+			}
+			return "", fmt.Errorf("write %s to %s failed, %v", value.Value, value.Path, err)
+		}
+		log.Infof(ctx, "io limit of %s is set to %s", value.Path, value.Value)
+		result = append(result, value.Value)
+	}
+	return strings.Join(result, "; "), nil
+}
+
+// parseIOMax returns the limits of the device in io.max and its line, the line of all keys max
+// is returned if the device is not limited. The format of a line is:
+// 8:16 rbps=2097152 wbps=max riops=max wiops=120
+func parseIOMax(content, device string) (map[string]string, string) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != device {
+			continue
+		}
+		current := make(map[string]string, len(fields)-1)
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				current[key] = value
+			}
+		}
+		return current, strings.TrimSpace(line)
+	}
+	return map[string]string{}, device + " rbps=max wbps=max riops=max wiops=max"
+}
+
+// checkLower returns an error if the current limit is not higher than the new one, the throttle
+// never loosens a limit
+func checkLower(file, key, current string, limit int64) error {
+	value, err := strconv.ParseInt(current, 10, 64)
+	if err == nil && value <= limit {
+		return fmt.Errorf("%s of %s is %s, it is not higher than %d", key, file, current, limit)
+	}
+	return nil
+}
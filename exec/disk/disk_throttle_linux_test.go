@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseIOMax(t *testing.T) {
+	content := "8:0 rbps=max wbps=max riops=max wiops=max\n8:16 rbps=2097152 wbps=max riops=max wiops=120\n"
+	current, line := parseIOMax(content, "8:16")
+	if line != "8:16 rbps=2097152 wbps=max riops=max wiops=120" || current["rbps"] != "2097152" || current["wiops"] != "120" {
+		t.Errorf("unexpected limits of 8:16: %v, line: %s", current, line)
+	}
+	current, line = parseIOMax(content, "8:32")
+	if line != "8:32 rbps=max wbps=max riops=max wiops=max" || len(current) != 0 {
+		t.Errorf("unexpected limits of 8:32: %v, line: %s", current, line)
+	}
+}
+
+func TestBlockDevice(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("write %s failed, %v", file, err)
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(file, &stat); err != nil {
+		t.Fatalf("stat %s failed, %v", file, err)
+	}
+	device := fmt.Sprintf("%d:%d", unix.Major(stat.Dev), unix.Minor(stat.Dev))
+
+	sysfs := t.TempDir()
+	sysDevBlock = filepath.Join(sysfs, "dev", "block")
+	defer func() { sysDevBlock = "/sys/dev/block" }()
+	if _, err := blockDevice(file); err == nil {
+		t.Errorf("expected error of the device not in %s", sysDevBlock)
+	}
+	// the device of the file is a partition of the fake disk 254:0
+	partition := filepath.Join(sysfs, "devices", "block", "vda", "vda1")
+	if err := os.MkdirAll(partition, 0o755); err != nil {
+		t.Fatalf("create %s failed, %v", partition, err)
+	}
+	if err := os.MkdirAll(sysDevBlock, 0o755); err != nil {
+		t.Fatalf("create %s failed, %v", sysDevBlock, err)
+	}
+	if err := os.Symlink(partition, filepath.Join(sysDevBlock, device)); err != nil {
+		t.Fatalf("link %s failed, %v", device, err)
+	}
+	if got, err := blockDevice(file); err != nil || got != device {
+		t.Errorf("unexpected device: %s, %v, expected: %s", got, err, device)
+	}
+	for name, value := range map[string]string{filepath.Join(partition, "partition"): "1\n", filepath.Join(partition, "..", "dev"): "254:0\n"} {
+		if err := os.WriteFile(name, []byte(value), 0o644); err != nil {
+			t.Fatalf("write %s failed, %v", name, err)
+		}
+	}
+	if got, err := blockDevice(file); err != nil || got != "254:0" {
+		t.Errorf("unexpected disk of the partition: %s, %v, expected: 254:0", got, err)
+	}
+}
+
+func TestParseIOLimits(t *testing.T) {
+	limits, resp := parseIOLimits(context.Background(), map[string]string{"write-bps": "0.5", "read-iops": "100"})
+	if resp != nil {
+		t.Fatalf("parse io limits failed, %s", resp.Err)
+	}
+	if *limits != (ioLimits{writeBps: 512 << 10, readIops: 100}) {
+		t.Errorf("unexpected io limits: %+v", limits)
+	}
+	if list := limits.list(); len(list) != 2 || list[0].key != "wbps" || list[1].key != "riops" {
+		t.Errorf("unexpected io limit list: %+v", list)
+	}
+	for _, flags := range []map[string]string{{}, {"read-bps": "0"}, {"write-iops": "1.5"}} {
+		if _, resp := parseIOLimits(context.Background(), flags); resp == nil {
+			t.Errorf("expected error of the flags %v", flags)
+		}
+	}
+}
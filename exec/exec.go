@@ -19,9 +19,11 @@ package exec
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/channel"
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
 	"github.com/chaosblade-io/chaosblade-spec-go/spec"
 )
 
@@ -64,3 +66,36 @@ func CheckFilepathExists(ctx context.Context, cl spec.Channel, filepath string)
 	}
 	return false
 }
+
+// TargetPid returns the host pid of the target process by the pid or process flag, or the nsexec target
+func TargetPid(ctx context.Context, c spec.Channel, model *spec.ExpModel) (int, *spec.Response) {
+	pidStr := model.ActionFlags["pid"]
+	process := model.ActionFlags["process"]
+	if pidStr == "" && process != "" {
+		// search on the host, the pids in the target namespace can not be used to find the cgroup
+		pids, err := cl.GetPidsByProcessName(process, ctx)
+		if err != nil {
+			log.Errorf(ctx, "%s", spec.ProcessIdByNameFailed.Sprintf(process, err))
+			return 0, spec.ResponseFailWithFlags(spec.ProcessIdByNameFailed, process, err)
+		}
+		if len(pids) == 0 {
+			return 0, spec.ResponseFailWithFlags(spec.ParameterInvalidProName, "process", process)
+		}
+		pidStr = pids[0]
+	}
+	if pidStr == "" {
+		if _, ok := c.(*channel.NSExecChannel); ok {
+			pidStr = model.ActionFlags[channel.NSTargetFlagName]
+		}
+	}
+	if pidStr == "" {
+		log.Errorf(ctx, "pid|process is nil")
+		return 0, spec.ResponseFailWithFlags(spec.ParameterLess, "pid|process")
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid <= 0 {
+		log.Errorf(ctx, "`%s`: pid is illegal, it must be a positive integer", pidStr)
+		return 0, spec.ResponseFailWithFlags(spec.ParameterIllegal, "pid", pidStr, "it must be a positive integer")
+	}
+	return pid, nil
+}
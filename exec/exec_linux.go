@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/containerd/cgroups"
 )
@@ -44,6 +46,22 @@ func PidPath(pid int) cgroups.Path {
 	}
 }
 
+// HasCapability returns true if the capability is in the effective set of the process
+func HasCapability(capability uint) bool {
+	content, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		capEff, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+		return err == nil && capEff&(1<<capability) != 0
+	}
+	return false
+}
+
 func Hierarchy(root string) func() ([]cgroups.Subsystem, error) {
 	return func() ([]cgroups.Subsystem, error) {
 		subsystems, err := defaults(root)
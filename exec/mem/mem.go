@@ -18,17 +18,14 @@ package mem
 
 import (
 	"context"
-	"fmt"
 	"math"
-	"os"
-	"path"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/chaosblade-io/chaosblade-spec-go/channel"
 	"github.com/chaosblade-io/chaosblade-spec-go/log"
 	"github.com/chaosblade-io/chaosblade-spec-go/spec"
-	"github.com/chaosblade-io/chaosblade-spec-go/util"
 
 	"github.com/chaosblade-io/chaosblade-exec-os/exec"
 	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
@@ -36,6 +33,13 @@ import (
 
 const BurnMemBin = "chaos_burnmem"
 
+const (
+	ModeMmapAnon = "mmap-anon"
+	ModeHugetlb  = "hugetlb"
+	ModeTHP      = "thp"
+	ModePSI      = "psi"
+)
+
 type MemCommandModelSpec struct {
 	spec.BaseExpModelCommandSpec
 }
@@ -47,7 +51,43 @@ func NewMemCommandModelSpec() spec.ExpModelCommandSpec {
 				&MemLoadActionCommand{
 					spec.BaseExpActionCommandSpec{
 						ActionMatchers: []spec.ExpFlagSpec{},
-						ActionFlags:    []spec.ExpFlagSpec{},
+						ActionFlags: []spec.ExpFlagSpec{
+							&spec.ExpFlag{
+								Name:     "hugepage-size",
+								Desc:     "hugepage size of hugetlb mode, for example 2M or 1G, default value is the Hugepagesize of /proc/meminfo",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "numa-node",
+								Desc:     "numa nodes to allocate the memory from, for example 0 or 0-1, the mem-percent and reserve flags are of the memory of the nodes. mem bandwidth accepts a single node",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "numa-policy",
+								Desc:     "memory policy of the numa-node flag, bind, preferred or interleave, default value is bind. preferred accepts a single node",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "psi-target",
+								Desc:     "memory pressure stall percent of psi mode (0-100), the allocation grows or shrinks every second to hold the avg10 of /proc/pressure/memory, or memory.pressure of the target cgroup, at it",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:     "psi-type",
+								Desc:     "memory pressure stall type of psi mode, some or full, default value is some",
+								Required: false,
+							},
+							&spec.ExpFlag{
+								Name:   "lock",
+								Desc:   "Lock the memory of ram mode by mlock as it is allocated, so that it is neither swapped nor compressed, the size must not exceed RLIMIT_MEMLOCK without CAP_IPC_LOCK",
+								NoArgs: true,
+							},
+							&spec.ExpFlag{
+								Name:     "touch-interval",
+								Desc:     "touch every page of the memory of ram mode every touch-interval seconds, so that the swapped or compressed pages are brought back",
+								Required: false,
+							},
+						},
 						ActionExecutor: &memExecutor{},
 						ActionExample: `
 # The execution memory footprint is 50%
@@ -66,12 +106,37 @@ blade create mem load --mode ram --mem-percent 50 --avoid-being-killed
 blade create mem load --mode ram --mem-percent 50 --timeout 200
 
 # 200M memory is reserved
-blade create mem load --mode ram --reserve 200 --rate 100`,
+blade create mem load --mode ram --reserve 200 --rate 100
+
+# The execution memory footprint is 50%, by anonymous mmap regions with transparent hugepages
+blade create mem load --mode thp --mem-percent 50
+
+# Exhaust the pool of 1G hugepages
+blade create mem load --mode hugetlb --hugepage-size 1G --mem-percent 100
+
+# The execution memory footprint is 50%, locked in memory
+blade create mem load --mode ram --mem-percent 50 --lock
+
+# The execution memory footprint is 50%, touched every 5 seconds to keep it resident
+blade create mem load --mode ram --mem-percent 50 --touch-interval 5
+
+# Exhaust the memory of the numa node 1, the other allocations fall back to the other nodes
+blade create mem load --mode ram --mem-percent 100 --numa-node 1
+
+# Hold the memory pressure stall at some avg10=20
+blade create mem load --mode psi --psi-target 20`,
 						ActionPrograms:    []string{BurnMemBin},
 						ActionCategories:  []string{category.SystemMem},
 						ActionProcessHang: true,
 					},
 				},
+				NewMemLeakActionCommand(),
+				NewMemBandwidthActionCommand(),
+				NewMemPressureActionCommand(),
+				NewMemOOMActionCommand(),
+				NewMemCacheEvictActionCommand(),
+				NewMemSwapActionCommand(),
+				NewMemSwapoffActionCommand(),
 			},
 			ExpFlags: []spec.ExpFlagSpec{
 				&spec.ExpFlag{
@@ -86,12 +151,12 @@ blade create mem load --mode ram --reserve 200 --rate 100`,
 				},
 				&spec.ExpFlag{
 					Name:     "rate",
-					Desc:     "burn memory rate, unit is M/S, only support for ram mode.",
+					Desc:     "burn memory rate, unit is M/S, it is also the leak rate of mem leak and the fill rate of mem swap.",
 					Required: false,
 				},
 				&spec.ExpFlag{
 					Name:     "mode",
-					Desc:     "burn memory mode, cache or ram.",
+					Desc:     "burn memory mode, cache, ram, mmap-anon, hugetlb, thp or psi. The mem-percent and reserve flags of hugetlb mode are of the hugepage pool",
 					Required: false,
 				},
 				&spec.ExpFlag{
@@ -101,7 +166,7 @@ blade create mem load --mode ram --reserve 200 --rate 100`,
 				},
 				&spec.ExpFlag{
 					Name:   "avoid-being-killed",
-					Desc:   "Prevent mem-burn process from being killed by oom-killer",
+					Desc:   "Prevent mem-burn process from being killed by oom-killer, its oom_score_adj is set to -1000, in the pid namespace of the target of nsexec as well",
 					NoArgs: true,
 				},
 				&spec.ExpFlag{
@@ -159,8 +224,8 @@ func (*MemLoadActionCommand) Matchers() []spec.ExpFlagSpec {
 	return []spec.ExpFlagSpec{}
 }
 
-func (*MemLoadActionCommand) Flags() []spec.ExpFlagSpec {
-	return []spec.ExpFlagSpec{}
+func (m *MemLoadActionCommand) Flags() []spec.ExpFlagSpec {
+	return m.ActionFlags
 }
 
 type memExecutor struct {
@@ -176,24 +241,18 @@ func (ce *memExecutor) SetChannel(channel spec.Channel) {
 }
 
 const (
-	// processOOMScoreAdj = "/proc/%s/oom_score_adj"
-	// oomMinScore        = "-1000"
-	processOOMAdj = "/proc/%d/oom_adj"
-	oomMinAdj     = "-17"
+	processOOMScoreAdj = "/proc/%d/oom_score_adj"
+	oomMinScore        = "-1000"
+	oomMaxScore        = "1000"
 )
 
 func (ce *memExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
-	commands := []string{"dd", "mount", "umount"}
-	if response, ok := ce.channel.IsAllCommandsAvailable(ctx, commands); !ok {
-		return response
-	}
-
 	if ce.channel == nil {
 		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
 		return spec.ResponseFailWithFlags(spec.ChannelNil)
 	}
 	if _, ok := spec.IsDestroy(ctx); ok {
-		return ce.stop(ctx, model.ActionFlags["mode"])
+		return ce.stop(ctx)
 	}
 	var memPercent, memReserve, memRate int
 
@@ -231,8 +290,43 @@ func (ce *memExecutor) Exec(uid string, ctx context.Context, model *spec.ExpMode
 			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "rate", memRateStr, "it must be a positive integer")
 		}
 	}
+	var hugepageSize int64
+	switch burnMemModeStr {
+	case "", "ram", "cache":
+	case ModeMmapAnon, ModeHugetlb, ModeTHP:
+		if hugepageSizeStr := model.ActionFlags["hugepage-size"]; hugepageSizeStr != "" {
+			hugepageSize = parseHugepageSize(hugepageSizeStr)
+			if hugepageSize <= 0 {
+				log.Errorf(ctx, "`%s`: hugepage-size is illegal, it must be a power of 2 with unit K, M or G", hugepageSizeStr)
+				return spec.ResponseFailWithFlags(spec.ParameterIllegal, "hugepage-size", hugepageSizeStr, "it must be a power of 2 with unit K, M or G")
+			}
+		}
+		if hugepageSize, err = checkMmapMode(burnMemModeStr, hugepageSize); err != nil {
+			log.Errorf(ctx, "`%s`: mode is not available, %v", burnMemModeStr, err)
+			return spec.ResponseFailWithFlags(spec.ParameterInvalid, "mode", burnMemModeStr, err)
+		}
+	case ModePSI:
+	default:
+		log.Errorf(ctx, "`%s`: mode is illegal", burnMemModeStr)
+		return spec.ResponseFailWithFlags(spec.ParameterIllegal, "mode", burnMemModeStr, "it must be cache, ram, mmap-anon, hugetlb, thp or psi")
+	}
 	ctx = context.WithValue(ctx, "cgroup-root", model.ActionFlags["cgroup-root"])
-	ce.start(ctx, memPercent, memReserve, memRate, burnMemModeStr, includeBufferCache, avoidBeingKilled, ce.channel)
+	numa, resp := parseNumaSpec(ctx, model.ActionFlags)
+	if resp != nil {
+		return resp
+	}
+	ctx = context.WithValue(ctx, "numa", numa)
+	resident, resp := parseResidentSpec(ctx, model.ActionFlags, burnMemModeStr, memPercent, memReserve, includeBufferCache)
+	if resp != nil {
+		return resp
+	}
+	var psi *psiTarget
+	if burnMemModeStr == ModePSI {
+		if psi, resp = parsePSITarget(ctx, model.ActionFlags); resp != nil {
+			return resp
+		}
+	}
+	ce.start(ctx, memPercent, memReserve, memRate, burnMemModeStr, hugepageSize, includeBufferCache, avoidBeingKilled, psi, resident, ce.channel)
 	return spec.Success()
 }
 
@@ -261,24 +355,22 @@ func calculateMemSize(ctx context.Context, burnMemMode string, percent, reserve
 	return total / 1024 / 1024, expectSize, nil
 }
 
-var dirName = "burnmem_tmpfs"
-
-var tmpfsName = "chaos_burn"
-
-var fileName = "file"
+// cacheChunk is the size written to the cache file at a time, the rate of cache mode is in MB
+const cacheChunk = 1024 * 1024
 
-var fileCount = 1
-
-func burnMemWithCache(ctx context.Context, memPercent, memReserve, memRate int, burnMemMode string, includeBufferCache bool, cl spec.Channel) {
-	tmpfsPath := path.Join(util.GetProgramPath(), dirName)
-	filePath := path.Join(tmpfsPath, fileName)
-	// prepare tmpfs
-	cl.Run(ctx, "mkdir", fmt.Sprintf("-p %s", tmpfsPath))
-	cl.Run(ctx, "mount", fmt.Sprintf("-t tmpfs -o size=100%% %s %s", tmpfsName, tmpfsPath))
+// burnMemWithCache fills an anonymous shmem file, the pages are accounted as buffer/cache and
+// released by the kernel as soon as the process exits, so nothing is left to clean up.
+func burnMemWithCache(ctx context.Context, memPercent, memReserve, memRate int, burnMemMode string, includeBufferCache bool) {
+	file, err := newCacheFile()
+	if err != nil {
+		log.Fatalf(ctx, "create the cache file failed, %v", err)
+	}
+	log.Infof(ctx, "burn mem with cache file %s", file.Name())
 
 	if memRate <= 0 {
 		memRate = 100
 	}
+	chunk := make([]byte, cacheChunk)
 	tick := time.Tick(time.Second)
 	for range tick {
 		_, expectMem, err := calculateMemSize(ctx, burnMemMode, memPercent, memReserve, includeBufferCache)
@@ -291,37 +383,67 @@ func burnMemWithCache(ctx context.Context, memPercent, memReserve, memRate int,
 				fillMem = int64(memRate)
 			}
 			log.Debugf(ctx, "burn mem with cache fill memory: %d", fillMem)
-			nFilePath := fmt.Sprintf("%s%d", filePath, fileCount)
-			response := cl.Run(ctx, "dd", fmt.Sprintf("if=/dev/zero of=%s bs=1M count=%d", nFilePath, fillMem))
-			if !response.Success {
-				log.Fatalf(ctx, "burn mem with cache err, %v", err)
+			for i := int64(0); i < fillMem; i++ {
+				if _, err := file.Write(chunk); err != nil {
+					log.Fatalf(ctx, "burn mem with cache err, %v", err)
+				}
 			}
-			fileCount++
 		}
 	}
 }
 
+// parseHugepageSize parses the size with unit K, M or G to bytes, it returns 0 if the size is
+// illegal or not a power of 2
+func parseHugepageSize(size string) int64 {
+	unit := int64(1)
+	switch strings.ToUpper(size[len(size)-1:]) {
+	case "K":
+		unit = 1 << 10
+	case "M":
+		unit = 1 << 20
+	case "G":
+		unit = 1 << 30
+	default:
+		return 0
+	}
+	value, err := strconv.ParseInt(size[:len(size)-1], 10, 64)
+	if err != nil || value <= 0 || value&(value-1) != 0 {
+		return 0
+	}
+	return value * unit
+}
+
 // start burn mem
-func (ce *memExecutor) start(ctx context.Context, memPercent, memReserve, memRate int, burnMemMode string, includeBufferCache bool, avoidBeingKilled bool, cl spec.Channel) {
-	// adjust process oom_score_adj to avoid being killed
+func (ce *memExecutor) start(ctx context.Context, memPercent, memReserve, memRate int, burnMemMode string, hugepageSize int64,
+	includeBufferCache bool, avoidBeingKilled bool, psi *psiTarget, resident *residentSpec, cl spec.Channel,
+) {
+	// adjust process oom_score_adj to avoid being killed, by /proc/self which also works in the pid
+	// namespace of the target of the nsexec channel
 	if avoidBeingKilled {
-		// not works for the channel.NSExecChannel
-		if _, ok := cl.(*channel.NSExecChannel); !ok {
-			scoreAdjFile := fmt.Sprintf(processOOMAdj, os.Getpid())
-			if _, err := os.Stat(scoreAdjFile); err == nil || os.IsExist(err) {
-				if err := os.WriteFile(scoreAdjFile, []byte(oomMinAdj), 0o644); err != nil { //nolint:gosec
-					log.Errorf(ctx, "run burn memory by %s mode failed, cannot edit the process oom_score_adj, %v", burnMemMode, err)
-				} else {
-					log.Infof(ctx, "write oom_adj %s to %s", oomMinAdj, scoreAdjFile)
-				}
-			} else {
-				log.Errorf(ctx, "score adjust file: %s not exists, %v", scoreAdjFile, err)
-			}
+		if err := avoidOOMKiller(ctx); err != nil {
+			log.Errorf(ctx, "run burn memory by %s mode failed, cannot edit the process oom_score_adj, %v", burnMemMode, err)
 		}
 	}
 
+	// the policy is of the thread, all of the modes touch their memory on this thread
+	if numa, ok := ctx.Value("numa").(*numaSpec); ok && numa != nil {
+		runtime.LockOSThread()
+		if err := applyNumaPolicy(numa); err != nil {
+			log.Fatalf(ctx, "set the memory policy failed, %v", err)
+		}
+		log.Infof(ctx, "memory policy %s of numa nodes %v is set", numa.policy, numa.nodes)
+	}
+
 	if burnMemMode == "cache" {
-		burnMemWithCache(ctx, memPercent, memReserve, memRate, burnMemMode, includeBufferCache, cl)
+		burnMemWithCache(ctx, memPercent, memReserve, memRate, burnMemMode, includeBufferCache)
+		return
+	}
+	if burnMemMode == ModeMmapAnon || burnMemMode == ModeHugetlb || burnMemMode == ModeTHP {
+		burnMemWithMmap(ctx, memPercent, memReserve, memRate, burnMemMode, hugepageSize, includeBufferCache)
+		return
+	}
+	if burnMemMode == ModePSI {
+		burnMemWithPressure(ctx, memRate, psi)
 		return
 	}
 	tick := time.Tick(time.Second)
@@ -331,7 +453,14 @@ func (ce *memExecutor) start(ctx context.Context, memPercent, memReserve, memRat
 	if memRate <= 0 {
 		memRate = 100
 	}
-	for range tick {
+	for ticks := 1; ; ticks++ {
+		<-tick
+		if resident.touchInterval > 0 && ticks%resident.touchInterval == 0 {
+			touchBlocks(cache)
+		}
+		if ticks%rssReportInterval == 0 {
+			reportRSS(ctx, cache)
+		}
 		_, expectMem, err := calculateMemSize(ctx, burnMemMode, memPercent, memReserve, includeBufferCache)
 		if err != nil {
 			log.Fatalf(ctx, "calculate memsize err, %v", err.Error())
@@ -356,18 +485,15 @@ func (ce *memExecutor) start(ctx context.Context, memPercent, memReserve, memRat
 			}
 			log.Debugf(ctx, "count: %d, len(buf): %d, cap(buf): %d, expect mem: %d, fill size: %d",
 				count, len(buf), cap(buf), expectMem, fillSize)
-			cache[count] = append(buf, make([]Block, fillSize)...)
+			if cache[count], err = growBlocks(buf, fillSize, resident.lock); err != nil {
+				log.Fatalf(ctx, "lock the memory failed, %v", err)
+			}
 		}
 	}
 }
 
 // stop burn mem
-func (ce *memExecutor) stop(ctx context.Context, burnMemMode string) *spec.Response {
+func (ce *memExecutor) stop(ctx context.Context) *spec.Response {
 	ctx = context.WithValue(ctx, "bin", BurnMemBin)
-	response := exec.Destroy(ctx, ce.channel, "mem load")
-	// umount tmpfs
-	ce.channel.Run(ctx, "umount", tmpfsName)
-	tmpfsPath := path.Join(util.GetProgramPath(), dirName)
-	ce.channel.Run(ctx, "rm", fmt.Sprintf("-rf %s", tmpfsPath))
-	return response
+	return exec.Destroy(ctx, ce.channel, "mem load")
 }
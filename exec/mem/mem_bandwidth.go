@@ -0,0 +1,309 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/shirou/gopsutil/cpu"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const BandwidthMemBin = "chaos_bandwidthmem"
+
+const (
+	KernelRead  = "read"
+	KernelWrite = "write"
+	KernelCopy  = "copy"
+)
+
+const (
+	// defaultBandwidthBuffer is the buffer size of a thread in MB if the last level cache is unknown
+	defaultBandwidthBuffer = 64
+	// bandwidthChunk is the bytes streamed between two checks of the throttle
+	bandwidthChunk = 1 << 20
+	// bandwidthReportInterval is the interval of the bandwidth in the log
+	bandwidthReportInterval = 5 * time.Second
+)
+
+// bandwidthSink receives the sums of the read kernel
+var bandwidthSink uint64
+
+type MemBandwidthActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemBandwidthActionCommand() spec.ExpActionCommandSpec {
+	return &MemBandwidthActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "threads",
+					Desc: "number of the streaming threads, default value is the number of cpus",
+				},
+				&spec.ExpFlag{
+					Name: "kernel",
+					Desc: "streaming kernel: read, write or copy, default value is copy",
+				},
+				&spec.ExpFlag{
+					Name: "target-bandwidth",
+					Desc: "total bandwidth of the threads, unit is GB/s, the threads stream as fast as they can if it is not set",
+				},
+				&spec.ExpFlag{
+					Name: "buffer-size",
+					Desc: "total buffer size of the threads, unit is MB, default value is twice the size of the last level cache per thread, so that the buffer of every thread is larger than the cache",
+				},
+				&spec.ExpFlag{
+					Name: "numa-node",
+					Desc: "run the threads on the cpus of the numa node and allocate their buffers on its memory, for example 1",
+				},
+			},
+			ActionExecutor: &memBandwidthExecutor{},
+			ActionExample: `
+# Saturate the memory bandwidth by copy on all of the cpus
+blade create mem bandwidth
+
+# Read 10GB/s by 4 threads
+blade create mem bandwidth --kernel read --threads 4 --target-bandwidth 10
+
+# Write on the cpus and the memory of the numa node 1
+blade create mem bandwidth --kernel write --numa-node 1`,
+			ActionPrograms:    []string{BandwidthMemBin},
+			ActionCategories:  []string{category.SystemMem},
+			ActionProcessHang: true,
+		},
+	}
+}
+
+func (*MemBandwidthActionCommand) Name() string {
+	return "bandwidth"
+}
+
+func (*MemBandwidthActionCommand) Aliases() []string {
+	return []string{"bw"}
+}
+
+func (*MemBandwidthActionCommand) ShortDesc() string {
+	return "Memory bandwidth saturation"
+}
+
+func (b *MemBandwidthActionCommand) LongDesc() string {
+	if b.ActionLongDesc != "" {
+		return b.ActionLongDesc
+	}
+	return "Stream the buffers larger than the last level cache by read, write or copy kernels on the threads, " +
+		"to make a memory bandwidth noisy neighbor. The numa-node flag runs the threads on the cpus of a single node and " +
+		"allocates the buffers from it. The bandwidth is reported to the log every 5 seconds"
+}
+
+type memBandwidthExecutor struct {
+	channel spec.Channel
+}
+
+func (*memBandwidthExecutor) Name() string {
+	return "bandwidth"
+}
+
+func (be *memBandwidthExecutor) SetChannel(channel spec.Channel) {
+	be.channel = channel
+}
+
+// bandwidthSpec holds the parsed flags of mem bandwidth, numaNode is -1 if it is not bound
+type bandwidthSpec struct {
+	threads    int
+	kernel     string
+	target     float64
+	bufferSize int64
+	numaNode   int
+}
+
+func (be *memBandwidthExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if be.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		ctx = context.WithValue(ctx, "bin", BandwidthMemBin)
+		return exec.Destroy(ctx, be.channel, "mem bandwidth")
+	}
+
+	bw := &bandwidthSpec{threads: runtime.NumCPU(), kernel: KernelCopy, numaNode: -1}
+	if kernel := model.ActionFlags["kernel"]; kernel != "" {
+		if kernel != KernelRead && kernel != KernelWrite && kernel != KernelCopy {
+			log.Errorf(ctx, "`%s`: kernel is illegal, it must be read, write or copy", kernel)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "kernel", kernel, "it must be read, write or copy")
+		}
+		bw.kernel = kernel
+	}
+	if threadsStr := model.ActionFlags["threads"]; threadsStr != "" {
+		threads, err := strconv.Atoi(threadsStr)
+		if err != nil || threads <= 0 {
+			log.Errorf(ctx, "`%s`: threads is illegal, it must be a positive integer", threadsStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "threads", threadsStr, "it must be a positive integer")
+		}
+		bw.threads = threads
+	}
+	if targetStr := model.ActionFlags["target-bandwidth"]; targetStr != "" {
+		target, err := strconv.ParseFloat(targetStr, 64)
+		if err != nil || target <= 0 || math.IsInf(target, 0) {
+			log.Errorf(ctx, "`%s`: target-bandwidth is illegal, it must be a positive number", targetStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "target-bandwidth", targetStr, "it must be a positive number")
+		}
+		bw.target = target
+	}
+	if bufferSizeStr := model.ActionFlags["buffer-size"]; bufferSizeStr != "" {
+		bufferSize, err := strconv.ParseInt(bufferSizeStr, 10, 64)
+		if err != nil || bufferSize <= 0 {
+			log.Errorf(ctx, "`%s`: buffer-size is illegal, it must be a positive integer", bufferSizeStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "buffer-size", bufferSizeStr, "it must be a positive integer")
+		}
+		bw.bufferSize = bufferSize
+	} else {
+		// the cache size of /proc/cpuinfo is the last level cache of a socket, every thread streams
+		// twice of it, so that the buffers miss the cache however the threads are spread on the sockets
+		threadBuffer := int64(defaultBandwidthBuffer)
+		if infos, err := cpu.Info(); err == nil && len(infos) > 0 && infos[0].CacheSize > 0 {
+			threadBuffer = max(int64(infos[0].CacheSize)*2/1024, 1)
+		}
+		bw.bufferSize = threadBuffer * int64(bw.threads)
+	}
+	if nodeStr := model.ActionFlags["numa-node"]; nodeStr != "" {
+		node, err := strconv.Atoi(nodeStr)
+		if err != nil || node < 0 {
+			log.Errorf(ctx, "`%s`: numa-node is illegal, it must be a non-negative integer", nodeStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "numa-node", nodeStr, "it must be a non-negative integer")
+		}
+		if _, err := nodeCpus(node); err != nil {
+			log.Errorf(ctx, "`%s`: numa-node is invalid, %v", nodeStr, err)
+			return spec.ResponseFailWithFlags(spec.ParameterInvalid, "numa-node", nodeStr, err)
+		}
+		bw.numaNode = node
+	}
+	return streamMem(ctx, bw)
+}
+
+// streamMem runs the kernel on every thread, the buffer of a thread is allocated after the
+// thread is bound to the numa node, so that its pages are from the node.
+func streamMem(ctx context.Context, bw *bandwidthSpec) *spec.Response {
+	threadBuffer := threadBufferSize(bw.bufferSize, bw.threads)
+	// bytes per second of a thread, 0 if it is not throttled
+	threadTarget := bw.target * (1 << 30) / float64(bw.threads)
+	log.Infof(ctx, "mem bandwidth by %s kernel, threads: %d, buffer of a thread: %d bytes, target: %fGB/s, numa node: %d",
+		bw.kernel, bw.threads, threadBuffer, bw.target, bw.numaNode)
+
+	runtime.GOMAXPROCS(bw.threads + 1)
+	var streamed atomic.Int64
+	bound := make(chan error, bw.threads)
+	for i := 0; i < bw.threads; i++ {
+		go func() {
+			if bw.numaNode >= 0 {
+				// the thread is never unlocked, so it exits with the goroutine instead of being reused
+				runtime.LockOSThread()
+				if err := bindNumaNode(bw.numaNode); err != nil {
+					bound <- err
+					return
+				}
+			}
+			buf := make([]byte, threadBuffer)
+			// touch the buffer, so that its pages are allocated by the thread
+			for j := range buf {
+				buf[j] = byte(j)
+			}
+			bound <- nil
+			stream(bw.kernel, buf, threadTarget, &streamed)
+		}()
+	}
+	for i := 0; i < bw.threads; i++ {
+		if err := <-bound; err != nil {
+			log.Errorf(ctx, "start the streaming thread failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("start the streaming thread failed, %v", err))
+		}
+	}
+
+	ticker := time.NewTicker(bandwidthReportInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		bytes := streamed.Swap(0)
+		log.Infof(ctx, "mem bandwidth: %.2fGB/s", float64(bytes)/(1<<30)/bandwidthReportInterval.Seconds())
+	}
+	return spec.Success()
+}
+
+// threadBufferSize returns the buffer bytes of a thread from the total buffer in MB. The buffer
+// is split into the source and destination halves of copy, so it is an even number of chunks,
+// which keeps a chunk of a half within the half.
+func threadBufferSize(bufferSize int64, threads int) int64 {
+	return max(bufferSize*1024*1024/int64(threads)/(2*bandwidthChunk), 1) * 2 * bandwidthChunk
+}
+
+// stream runs the kernel over the buffer chunk by chunk forever, and sleeps if the thread is
+// ahead of the target. The bytes read and written are added to streamed.
+func stream(kernel string, buf []byte, target float64, streamed *atomic.Int64) {
+	half := len(buf) / 2
+	startTime := time.Now()
+	var total float64
+	for offset := 0; ; offset += bandwidthChunk {
+		var moved int
+		switch kernel {
+		case KernelRead:
+			offset %= len(buf)
+			// the sum is published, so that the reads are not dropped by the compiler
+			atomic.AddUint64(&bandwidthSink, readChunk(buf[offset:offset+bandwidthChunk]))
+			moved = bandwidthChunk
+		case KernelWrite:
+			offset %= len(buf)
+			clear(buf[offset : offset+bandwidthChunk])
+			moved = bandwidthChunk
+		default:
+			offset %= half
+			copy(buf[half+offset:half+offset+bandwidthChunk], buf[offset:offset+bandwidthChunk])
+			moved = 2 * bandwidthChunk
+		}
+		streamed.Add(int64(moved))
+		if target > 0 {
+			total += float64(moved)
+			if ahead := time.Duration(total/target*float64(time.Second)) - time.Since(startTime); ahead > 0 {
+				time.Sleep(ahead)
+			}
+		}
+	}
+}
+
+// readChunk sums the chunk by words, 4 at a time, so that it is bound by the memory instead of the loop
+func readChunk(chunk []byte) uint64 {
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(&chunk[0])), len(chunk)/8)
+	var s0, s1, s2, s3 uint64
+	for i := 0; i+3 < len(words); i += 4 {
+		s0 += words[i]
+		s1 += words[i+1]
+		s2 += words[i+2]
+		s3 += words[i+3]
+	}
+	return s0 + s1 + s2 + s3
+}
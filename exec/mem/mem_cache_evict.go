@@ -0,0 +1,221 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const EvictMemBin = "chaos_evictmem"
+
+const (
+	// defaultDropLevel drops the page cache, dentries and inodes
+	defaultDropLevel = 3
+	// defaultEvictInterval is the seconds between two evictions
+	defaultEvictInterval = 10
+)
+
+type MemCacheEvictActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemCacheEvictActionCommand() spec.ExpActionCommandSpec {
+	return &MemCacheEvictActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "level",
+					Desc: "drop_caches level: 1 drops the page cache, 2 drops the dentries and inodes, 3 drops both, default value is 3",
+				},
+				&spec.ExpFlag{
+					Name: "path",
+					Desc: "files or directories to evict from the page cache by posix_fadvise DONTNEED, split by comma, the directories are walked recursively. drop_caches is used if it is not set",
+				},
+				&spec.ExpFlag{
+					Name: "interval",
+					Desc: "seconds between two evictions, default value is 10",
+				},
+			},
+			ActionExecutor: &memCacheEvictExecutor{},
+			ActionExample: `
+# Drop the page cache, dentries and inodes every 10 seconds
+blade create mem cache-evict
+
+# Drop the page cache every second
+blade create mem cache-evict --level 1 --interval 1
+
+# Evict the files of the mysql data directory from the page cache every 5 seconds
+blade create mem cache-evict --path /var/lib/mysql --interval 5`,
+			ActionPrograms:    []string{EvictMemBin},
+			ActionCategories:  []string{category.SystemMem},
+			ActionProcessHang: true,
+		},
+	}
+}
+
+func (*MemCacheEvictActionCommand) Name() string {
+	return "cache-evict"
+}
+
+func (*MemCacheEvictActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemCacheEvictActionCommand) ShortDesc() string {
+	return "Evict the page cache"
+}
+
+func (c *MemCacheEvictActionCommand) LongDesc() string {
+	if c.ActionLongDesc != "" {
+		return c.ActionLongDesc
+	}
+	return "Drop the caches of the host by /proc/sys/vm/drop_caches, or evict the pages of the files in the path flag by posix_fadvise DONTNEED, " +
+		"repeatedly on the interval until destroyed, to simulate cold cache. The dirty pages are written back before drop_caches, " +
+		"the dirty pages of the files in the path flag are not evicted"
+}
+
+type memCacheEvictExecutor struct {
+	channel spec.Channel
+}
+
+func (*memCacheEvictExecutor) Name() string {
+	return "cache-evict"
+}
+
+func (ce *memCacheEvictExecutor) SetChannel(channel spec.Channel) {
+	ce.channel = channel
+}
+
+func (ce *memCacheEvictExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if ce.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		ctx = context.WithValue(ctx, "bin", EvictMemBin)
+		return exec.Destroy(ctx, ce.channel, "mem cache-evict")
+	}
+
+	levelStr := model.ActionFlags["level"]
+	pathStr := model.ActionFlags["path"]
+	if levelStr != "" && pathStr != "" {
+		log.Errorf(ctx, "level and path can not be used together")
+		return spec.ResponseFailWithFlags(spec.ParameterIllegal, "level", levelStr, "it can not be used together with path")
+	}
+	level := defaultDropLevel
+	if levelStr != "" {
+		var err error
+		if level, err = strconv.Atoi(levelStr); err != nil || level < 1 || level > 3 {
+			log.Errorf(ctx, "`%s`: level is illegal, it must be 1, 2 or 3", levelStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "level", levelStr, "it must be 1, 2 or 3")
+		}
+	}
+	interval := defaultEvictInterval
+	if intervalStr := model.ActionFlags["interval"]; intervalStr != "" {
+		var err error
+		if interval, err = strconv.Atoi(intervalStr); err != nil || interval <= 0 {
+			log.Errorf(ctx, "`%s`: interval is illegal, it must be a positive integer", intervalStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "interval", intervalStr, "it must be a positive integer")
+		}
+	}
+	var paths []string
+	if pathStr != "" {
+		for _, path := range strings.Split(pathStr, ",") {
+			path = strings.TrimSpace(path)
+			if _, err := os.Stat(path); err != nil {
+				log.Errorf(ctx, "`%s`: path is invalid, %v", path, err)
+				return spec.ResponseFailWithFlags(spec.ParameterInvalid, "path", path, err)
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	// the first eviction fails the experiment, for example drop_caches is read-only in a container
+	if err := evictCaches(ctx, level, paths); err != nil {
+		log.Errorf(ctx, "%v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := evictCaches(ctx, level, paths); err != nil {
+			log.Warnf(ctx, "%v", err)
+		}
+	}
+	return spec.Success()
+}
+
+// evictCaches evicts the files in the paths, or drops the caches of the level if there is no path.
+// It returns an error if the caches can not be dropped or no file is evicted.
+func evictCaches(ctx context.Context, level int, paths []string) error {
+	if paths == nil {
+		if err := dropCaches(level); err != nil {
+			return fmt.Errorf("drop caches of level %d failed, %v", level, err)
+		}
+		log.Infof(ctx, "caches of level %d are dropped", level)
+		return nil
+	}
+	files, bytes := evictPaths(ctx, paths)
+	if files == 0 {
+		return fmt.Errorf("no file in %s is evicted", strings.Join(paths, ","))
+	}
+	log.Infof(ctx, "%d files of %d bytes are evicted from the page cache", files, bytes)
+	return nil
+}
+
+// evictPaths evicts every regular file in the paths, a file which fails is logged and skipped.
+// It returns the number and the total size of the evicted files.
+func evictPaths(ctx context.Context, paths []string) (int, int64) {
+	var files int
+	var bytes int64
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				log.Warnf(ctx, "walk %s failed, %v", path, err)
+				return nil
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			size, err := evictFile(path)
+			if err != nil {
+				log.Warnf(ctx, "evict %s failed, %v", path, err)
+				return nil
+			}
+			files++
+			bytes += size
+			return nil
+		})
+		if err != nil {
+			log.Warnf(ctx, "walk %s failed, %v", root, err)
+		}
+	}
+	return files, bytes
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+const dropCachesFile = "/proc/sys/vm/drop_caches"
+
+// dropCaches writes the dirty pages back, so that they can be dropped, then drops the caches of the level
+func dropCaches(level int) error {
+	unix.Sync()
+	return os.WriteFile(dropCachesFile, []byte(strconv.Itoa(level)), 0o644) //nolint:gosec
+}
+
+// evictFile evicts the clean pages of the file from the page cache, it returns the file size
+func evictFile(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
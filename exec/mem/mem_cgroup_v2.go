@@ -155,3 +155,28 @@ func getSystemMemory(burnMemMode string, includeBufferCache bool) (int64, int64,
 	}
 	return total, available, nil
 }
+
+// pressureFile returns memory.pressure of the cgroup v2 of the target process if it is set,
+// otherwise /proc/pressure/memory of the host
+func pressureFile(ctx context.Context) (string, error) {
+	pid, ok := ctx.Value(channel.NSTargetFlagName).(string)
+	if !ok || pid == "" {
+		return procPressureMemory, nil
+	}
+	cgroupRoot, _ := ctx.Value("cgroup-root").(string)
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	if cgroups.DetectCGroupVersion(ctx, cgroupRoot) != cgroups.CGroupV2 {
+		// cgroup v1 has no pressure file of the cgroup
+		return procPressureMemory, nil
+	}
+	cgroupPath, err := cgroups.FindCGroupV2Path(ctx, pid, cgroupRoot)
+	if err != nil {
+		return "", err
+	}
+	if cgroupPath == "" {
+		return "", fmt.Errorf("cgroup v2 path of pid %s not found", pid)
+	}
+	return cgroupPath + "/memory.pressure", nil
+}
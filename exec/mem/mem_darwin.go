@@ -18,6 +18,9 @@ package mem
 
 import (
 	"context"
+	"errors"
+	"os"
+	"time"
 
 	"github.com/shirou/gopsutil/mem"
 )
@@ -35,3 +38,98 @@ func getAvailableAndTotal(ctx context.Context, burnMemMode string, includeBuffer
 	}
 	return total, available, nil
 }
+
+// newCacheFile creates an unlinked temporary file, there is no memfd on darwin
+func newCacheFile() (*os.File, error) {
+	file, err := os.CreateTemp("", BurnMemBin)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(file.Name()); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// checkMmapMode is not supported on darwin, the mmap modes are linux only
+func checkMmapMode(mode string, hugepageSize int64) (int64, error) {
+	return 0, errors.New("mmap modes are not supported on darwin")
+}
+
+func burnMemWithMmap(ctx context.Context, memPercent, memReserve, memRate int, mode string, hugepageSize int64, includeBufferCache bool) {
+}
+
+// nodeCpus is not supported on darwin, there is no numa api
+func nodeCpus(node int) ([]int, error) {
+	return nil, errors.New("numa is not supported on darwin")
+}
+
+func bindNumaNode(node int) error {
+	return errors.New("numa is not supported on darwin")
+}
+
+// pressureCgroup is not supported on darwin, there is no cgroup
+func pressureCgroup(ctx context.Context, cgroupRoot, uid string, pid, memPercent int, hardLimit bool) (string, error) {
+	return "", errors.New("cgroup is not supported on darwin")
+}
+
+func oomCgroup(ctx context.Context, cgroupRoot string, pid int, victims []int, wait time.Duration) (*oomResult, error) {
+	return nil, errors.New("cgroup is not supported on darwin")
+}
+
+// dropCaches is not supported on darwin, there is no drop_caches
+func dropCaches(level int) error {
+	return errors.New("drop_caches is not supported on darwin")
+}
+
+func evictFile(path string) (int64, error) {
+	return 0, errors.New("posix_fadvise is not supported on darwin")
+}
+
+func enterSwapCgroup(ctx context.Context, cgroupRoot, uid string, resident int64) error {
+	return errors.New("cgroup is not supported on darwin")
+}
+
+func removeSwapCgroup(ctx context.Context, uid string) {
+}
+
+// swapon and swapoff are not supported on darwin, there is no /proc/swaps
+func swapon(path string, priority int) error {
+	return errors.New("swapon is not supported on darwin")
+}
+
+func swapoff(path string) error {
+	return errors.New("swapoff is not supported on darwin")
+}
+
+// pressureFile is not supported on darwin, there is no psi
+func pressureFile(ctx context.Context) (string, error) {
+	return "", errors.New("psi is not supported on darwin")
+}
+
+func applyNumaPolicy(numa *numaSpec) error {
+	return errors.New("numa is not supported on darwin")
+}
+
+func nodeMemory(nodes []int) (int64, int64, int64, error) {
+	return 0, 0, 0, errors.New("numa is not supported on darwin")
+}
+
+// checkMemLock is not supported on darwin, the lock flag is linux only
+func checkMemLock(size int64) error {
+	return errors.New("lock is not supported on darwin")
+}
+
+func lockBlocks(blocks []Block) error {
+	return errors.New("lock is not supported on darwin")
+}
+
+func unlockBlocks(blocks []Block) error {
+	return errors.New("lock is not supported on darwin")
+}
+
+// avoidOOMKiller is not supported on darwin, there is no oom killer
+func avoidOOMKiller(ctx context.Context) error {
+	return errors.New("oom_score_adj is not supported on darwin")
+}
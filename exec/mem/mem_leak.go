@@ -0,0 +1,247 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const LeakMemBin = "chaos_leakmem"
+
+const (
+	GrowthLinear      = "linear"
+	GrowthExponential = "exponential"
+	GrowthStep        = "step"
+)
+
+const (
+	defaultLeakRate     = 10
+	defaultGrowthFactor = 1.05
+	defaultStepInterval = 60
+	// maxLeakChunk is the MB allocated every second at most, the rest is leaked in the next seconds
+	maxLeakChunk = 1024
+)
+
+type MemLeakActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemLeakActionCommand() spec.ExpActionCommandSpec {
+	return &MemLeakActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "growth",
+					Desc: "growth curve of the leak: linear, exponential or step, default value is linear",
+				},
+				&spec.ExpFlag{
+					Name: "growth-factor",
+					Desc: "the leak rate of exponential growth is multiplied by the factor every second, default value is 1.05",
+				},
+				&spec.ExpFlag{
+					Name: "interval",
+					Desc: "seconds between two steps of step growth, every step leaks rate * interval MB at once, default value is 60",
+				},
+				&spec.ExpFlag{
+					Name: "max-size",
+					Desc: "cap of the leaked memory, unit is MB. The leak grows until it is OOM killed if it is not set",
+				},
+			},
+			ActionExecutor: &memLeakExecutor{},
+			ActionExample: `
+# Leak 10MB every second until the process is OOM killed
+blade create mem leak
+
+# Leak 5MB every second, and the rate grows by 10% every second, until 2GB is leaked
+blade create mem leak --growth exponential --rate 5 --growth-factor 1.1 --max-size 2048
+
+# Leak 100MB at once every 5 minutes for an hour
+blade create mem leak --growth step --rate 100 --interval 300 --timeout 3600`,
+			ActionPrograms:    []string{LeakMemBin},
+			ActionCategories:  []string{category.SystemMem},
+			ActionProcessHang: true,
+		},
+	}
+}
+
+func (*MemLeakActionCommand) Name() string {
+	return "leak"
+}
+
+func (*MemLeakActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemLeakActionCommand) ShortDesc() string {
+	return "Memory leak"
+}
+
+func (l *MemLeakActionCommand) LongDesc() string {
+	if l.ActionLongDesc != "" {
+		return l.ActionLongDesc
+	}
+	return "Grow the resident memory of the process by the growth curve, unlike mem load which holds a target, " +
+		"the leak never gives memory back, it is held after the max-size is reached until the process is OOM killed, " +
+		"destroyed or the timeout is up. At most 1GB is leaked every second, the rest is carried to the next seconds. " +
+		"The rate flag is the leak rate in MB/s, default value is 10"
+}
+
+type memLeakExecutor struct {
+	channel spec.Channel
+}
+
+func (*memLeakExecutor) Name() string {
+	return "leak"
+}
+
+func (le *memLeakExecutor) SetChannel(channel spec.Channel) {
+	le.channel = channel
+}
+
+// leakSpec holds the parsed flags of mem leak, the sizes are in MB
+type leakSpec struct {
+	growth   string
+	rate     float64
+	factor   float64
+	interval int
+	maxSize  int64
+}
+
+func (le *memLeakExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if le.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		ctx = context.WithValue(ctx, "bin", LeakMemBin)
+		return exec.Destroy(ctx, le.channel, "mem leak")
+	}
+
+	leak := &leakSpec{growth: GrowthLinear, rate: defaultLeakRate, factor: defaultGrowthFactor, interval: defaultStepInterval}
+	if growth := model.ActionFlags["growth"]; growth != "" {
+		if growth != GrowthLinear && growth != GrowthExponential && growth != GrowthStep {
+			log.Errorf(ctx, "`%s`: growth is illegal, it must be linear, exponential or step", growth)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "growth", growth, "it must be linear, exponential or step")
+		}
+		leak.growth = growth
+	}
+	if intervalStr := model.ActionFlags["interval"]; intervalStr != "" {
+		interval, err := strconv.Atoi(intervalStr)
+		if err != nil || interval <= 0 {
+			log.Errorf(ctx, "`%s`: interval is illegal, it must be a positive integer", intervalStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "interval", intervalStr, "it must be a positive integer")
+		}
+		leak.interval = interval
+	}
+	if rateStr := model.ActionFlags["rate"]; rateStr != "" {
+		rate, err := strconv.Atoi(rateStr)
+		if err != nil || rate <= 0 {
+			log.Errorf(ctx, "`%s`: rate is illegal, it must be a positive integer", rateStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "rate", rateStr, "it must be a positive integer")
+		}
+		leak.rate = float64(rate)
+	}
+	if factorStr := model.ActionFlags["growth-factor"]; factorStr != "" {
+		factor, err := strconv.ParseFloat(factorStr, 64)
+		if err != nil || factor < 1 || math.IsInf(factor, 0) {
+			log.Errorf(ctx, "`%s`: growth-factor is illegal, it must be a number not less than 1", factorStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "growth-factor", factorStr, "it must be a number not less than 1")
+		}
+		leak.factor = factor
+	}
+	if maxSizeStr := model.ActionFlags["max-size"]; maxSizeStr != "" {
+		maxSize, err := strconv.ParseInt(maxSizeStr, 10, 64)
+		if err != nil || maxSize <= 0 {
+			log.Errorf(ctx, "`%s`: max-size is illegal, it must be a positive integer", maxSizeStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "max-size", maxSizeStr, "it must be a positive integer")
+		}
+		leak.maxSize = maxSize
+	}
+	leakMem(ctx, leak)
+	return spec.Success()
+}
+
+// leakMem allocates the memory of every second and touches it, so that it is resident. The
+// allocated bytes are logged every second, so that the growth can be matched to the alerts. It
+// runs until the process is killed, by destroy or by the timeout.
+func leakMem(ctx context.Context, leak *leakSpec) {
+	var leaked [][]byte
+	var allocated, pending float64
+	pageSize := os.Getpagesize()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for elapsed := 1; ; elapsed++ {
+		<-ticker.C
+		var size int
+		size, pending = leak.chunk(elapsed, allocated, pending, pageSize)
+		if size == 0 {
+			continue
+		}
+		buf := make([]byte, size)
+		for i := 0; i < size; i += pageSize {
+			buf[i] = 1
+		}
+		leaked = append(leaked, buf)
+		allocated += float64(size)
+		log.Infof(ctx, "mem leak by %s growth, allocated: %d bytes", leak.growth, int64(allocated))
+		if leak.maxSize > 0 && allocated >= float64(leak.maxSize)*1024*1024 {
+			log.Infof(ctx, "mem leak reaches max-size %dMB, hold the memory", leak.maxSize)
+		}
+	}
+}
+
+// chunk returns the bytes allocated at the elapsed second, and the pending bytes carried to the
+// next second. It allocates whole pages and at most maxLeakChunk MB, so that a fast growth leaks
+// gradually rather than asking for more than the memory at once.
+func (l *leakSpec) chunk(elapsed int, allocated, pending float64, pageSize int) (int, float64) {
+	maxBytes := float64(l.maxSize) * 1024 * 1024
+	if l.maxSize > 0 && allocated >= maxBytes {
+		return 0, 0
+	}
+	// the exponential size overflows to +Inf, which is still carried as a float
+	pending += l.size(elapsed) * 1024 * 1024
+	if l.maxSize > 0 && allocated+pending > maxBytes {
+		pending = maxBytes - allocated
+	}
+	size := int(math.Min(pending, maxLeakChunk*1024*1024)) / pageSize * pageSize
+	return size, pending - float64(size)
+}
+
+// size returns the MB leaked at the elapsed second of the experiment
+func (l *leakSpec) size(elapsed int) float64 {
+	switch l.growth {
+	case GrowthExponential:
+		return l.rate * math.Pow(l.factor, float64(elapsed-1))
+	case GrowthStep:
+		if elapsed%l.interval == 0 {
+			return l.rate * float64(l.interval)
+		}
+		return 0
+	}
+	return l.rate
+}
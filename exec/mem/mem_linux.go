@@ -19,18 +19,25 @@ package mem
 import (
 	"context"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/chaosblade-io/chaosblade-spec-go/channel"
 	"github.com/chaosblade-io/chaosblade-spec-go/log"
 	"github.com/containerd/cgroups"
 	"github.com/shirou/gopsutil/mem"
+	"golang.org/x/sys/unix"
 
 	"github.com/chaosblade-io/chaosblade-exec-os/exec"
 	cgroupsv2 "github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
 )
 
 func getAvailableAndTotal(ctx context.Context, burnMemMode string, includeBufferCache bool) (int64, int64, error) {
+	if numa, ok := ctx.Value("numa").(*numaSpec); ok && numa != nil {
+		return getAvailableAndTotalOfNodes(ctx, burnMemMode, includeBufferCache, numa.nodes)
+	}
+
 	pid := ctx.Value(channel.NSTargetFlagName)
 	total := int64(0)
 	available := int64(0)
@@ -107,3 +114,113 @@ func getAvailableAndTotalV1(ctx context.Context, burnMemMode string, includeBuff
 	}
 	return total, available, nil
 }
+
+// getAvailableAndTotalOfNodes returns the memory of the numa nodes read from /sys/devices/system/node
+func getAvailableAndTotalOfNodes(ctx context.Context, burnMemMode string, includeBufferCache bool, nodes []int) (int64, int64, error) {
+	total, available, filePages, err := nodeMemory(nodes)
+	if err != nil {
+		return 0, 0, err
+	}
+	if burnMemMode == "ram" && !includeBufferCache {
+		available += filePages
+	}
+	log.Debugf(ctx, "numa nodes %v memory: total=%d, available=%d, file pages=%d", nodes, total, available, filePages)
+	return total, available, nil
+}
+
+// newCacheFile creates an anonymous shmem file by memfd_create, it needs neither a mount nor a
+// path, and its pages are freed when the process exits.
+func newCacheFile() (*os.File, error) {
+	fd, err := unix.MemfdCreate(BurnMemBin, unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "memfd:"+BurnMemBin), nil
+}
+
+const (
+	// capIpcLock is CAP_IPC_LOCK, the process with it is not limited by RLIMIT_MEMLOCK
+	capIpcLock = 14
+	// memLockHeadroomRatio reserves 1/memLockHeadroomRatio of the size in RLIMIT_MEMLOCK
+	memLockHeadroomRatio = 10
+)
+
+// checkMemLock returns an error if the size can not be locked, the RLIMIT_MEMLOCK is ignored if
+// the burner has CAP_IPC_LOCK.
+func checkMemLock(size int64) error {
+	if exec.HasCapability(capIpcLock) {
+		return nil
+	}
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &limit); err != nil {
+		return fmt.Errorf("get RLIMIT_MEMLOCK failed, %v", err)
+	}
+	// the blocks grow by the rate, the last growth may overshoot the size
+	required := size + size/memLockHeadroomRatio
+	if limit.Cur != unix.RLIM_INFINITY && uint64(required) > limit.Cur {
+		return fmt.Errorf("%d bytes with the headroom exceeds RLIMIT_MEMLOCK %d and CAP_IPC_LOCK is not granted",
+			required, limit.Cur)
+	}
+	return nil
+}
+
+// lockBlocks locks the pages of the blocks, so that they are neither swapped nor compressed
+func lockBlocks(blocks []Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	return unix.Mlock(blocksBytes(blocks))
+}
+
+// unlockBlocks unlocks the pages of the blocks, for example the ones moved by the append
+func unlockBlocks(blocks []Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	return unix.Munlock(blocksBytes(blocks))
+}
+
+// selfOOMScoreAdj is the oom_score_adj of the burner. /proc/self is resolved in the pid namespace
+// of the mounted /proc, so it is the burner under the nsexec channel as well, where os.Getpid is
+// the pid in the namespace of the target.
+var selfOOMScoreAdj = "/proc/self/oom_score_adj"
+
+// avoidOOMKiller sets the oom_score_adj of the burner to -1000
+func avoidOOMKiller(ctx context.Context) error {
+	if err := os.WriteFile(selfOOMScoreAdj, []byte(oomMinScore), 0o644); err != nil { //nolint:gosec
+		return err
+	}
+	log.Infof(ctx, "write oom_score_adj %s to %s, the pid in the namespace of the burner is %d", oomMinScore, selfOOMScoreAdj, os.Getpid())
+	return nil
+}
+
+// procPid returns the pid of the burner in the pid namespace of the mounted /proc, by which the
+// files of the burner in /proc are addressed. It is the first of the NSpid of /proc/self/status,
+// the os.Getpid is returned if the kernel has no NSpid. A pid written to cgroup.procs is of the
+// pid namespace of the burner instead, that is os.Getpid.
+func procPid() int {
+	content, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return os.Getpid()
+	}
+	if pid, ok := parseNSpid(string(content)); ok {
+		return pid
+	}
+	return os.Getpid()
+}
+
+// parseNSpid returns the outermost pid of the NSpid line, the format is: NSpid:	1234	5
+func parseNSpid(status string) (int, bool) {
+	for _, line := range strings.Split(status, "\n") {
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "NSpid:"))
+		if len(fields) == 0 {
+			return 0, false
+		}
+		pid, err := strconv.Atoi(fields[0])
+		return pid, err == nil
+	}
+	return 0, false
+}
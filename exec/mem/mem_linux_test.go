@@ -0,0 +1,75 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestParseNSpid(t *testing.T) {
+	tests := []struct {
+		status string
+		pid    int
+		ok     bool
+	}{
+		{"Name:\tchaos_os\nPid:\t5\nNSpid:\t1234\t5\n", 1234, true},
+		{"Name:\tchaos_os\nPid:\t1234\nNSpid:\t1234\n", 1234, true},
+		{"Name:\tchaos_os\nPid:\t1234\n", 0, false},
+	}
+	for _, tt := range tests {
+		if pid, ok := parseNSpid(tt.status); pid != tt.pid || ok != tt.ok {
+			t.Errorf("unexpected pid of %q: %d %v, expected: %d %v", tt.status, pid, ok, tt.pid, tt.ok)
+		}
+	}
+}
+
+func TestAvoidOOMKiller(t *testing.T) {
+	scoreAdjFile := filepath.Join(t.TempDir(), "oom_score_adj")
+	if err := os.WriteFile(scoreAdjFile, []byte("0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	origin := selfOOMScoreAdj
+	defer func() { selfOOMScoreAdj = origin }()
+
+	selfOOMScoreAdj = scoreAdjFile
+	if err := avoidOOMKiller(context.Background()); err != nil {
+		t.Fatalf("avoid the oom killer failed, %v", err)
+	}
+	if content, _ := os.ReadFile(scoreAdjFile); string(content) != oomMinScore {
+		t.Errorf("unexpected oom_score_adj: %q, expected %q", content, oomMinScore)
+	}
+
+	selfOOMScoreAdj = filepath.Join(t.TempDir(), "missing", "oom_score_adj")
+	if err := avoidOOMKiller(context.Background()); err == nil {
+		t.Errorf("expected the error of the missing oom_score_adj")
+	}
+}
+
+func TestProcPid(t *testing.T) {
+	// the link of /proc/self is the pid in the pid namespace of the mounted /proc
+	link, err := os.Readlink("/proc/self")
+	if err != nil {
+		t.Skipf("no /proc, %v", err)
+	}
+	if pid := procPid(); strconv.Itoa(pid) != link {
+		t.Errorf("unexpected pid: %d, expected %s", pid, link)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	hugepagesPath = "/sys/kernel/mm/hugepages"
+	thpEnabled    = "/sys/kernel/mm/transparent_hugepage/enabled"
+)
+
+// checkMmapMode returns the hugepage size used by the mode, the hugetlb mode needs a pool of
+// the hugepage size and the thp mode needs the transparent hugepage enabled or madvised.
+func checkMmapMode(mode string, hugepageSize int64) (int64, error) {
+	switch mode {
+	case ModeHugetlb:
+		if hugepageSize == 0 {
+			var err error
+			if hugepageSize, err = defaultHugepageSize(); err != nil {
+				return 0, fmt.Errorf("get the default hugepage size failed, %v", err)
+			}
+		}
+		if _, err := os.Stat(hugepagePoolPath(hugepageSize)); err != nil {
+			return 0, fmt.Errorf("hugepage size %dkB is not supported by the kernel", hugepageSize/1024)
+		}
+	case ModeTHP:
+		content, err := os.ReadFile(thpEnabled)
+		if err != nil {
+			return 0, fmt.Errorf("transparent hugepage is not supported by the kernel, %v", err)
+		}
+		if !strings.Contains(string(content), "[always]") && !strings.Contains(string(content), "[madvise]") {
+			return 0, fmt.Errorf("transparent hugepage is disabled, %s is %s", thpEnabled, strings.TrimSpace(string(content)))
+		}
+	}
+	return hugepageSize, nil
+}
+
+// defaultHugepageSize reads the Hugepagesize of /proc/meminfo, in bytes
+func defaultHugepageSize() (int64, error) {
+	content, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "Hugepagesize:" {
+			size, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return size * 1024, nil
+		}
+	}
+	return 0, fmt.Errorf("Hugepagesize not found in /proc/meminfo")
+}
+
+func hugepagePoolPath(hugepageSize int64) string {
+	return filepath.Join(hugepagesPath, fmt.Sprintf("hugepages-%dkB", hugepageSize/1024))
+}
+
+// hugepagePool returns the total and free pages of the hugepage pool, or of the pools of the
+// numa nodes if they are set
+func hugepagePool(hugepageSize int64, nodes []int) (int64, int64, error) {
+	pools := []string{hugepagePoolPath(hugepageSize)}
+	if len(nodes) > 0 {
+		pools = pools[:0]
+		for _, node := range nodes {
+			pools = append(pools, filepath.Join(nodeSysPath, fmt.Sprintf("node%d", node), "hugepages",
+				fmt.Sprintf("hugepages-%dkB", hugepageSize/1024)))
+		}
+	}
+	values := make([]int64, 2)
+	for _, pool := range pools {
+		for i, file := range []string{"nr_hugepages", "free_hugepages"} {
+			content, err := os.ReadFile(filepath.Join(pool, file))
+			if err != nil {
+				return 0, 0, err
+			}
+			value, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+			if err != nil {
+				return 0, 0, err
+			}
+			values[i] += value
+		}
+	}
+	return values[0], values[1], nil
+}
+
+// burnMemWithMmap maps anonymous regions every second and touches every page of them, so that
+// they are backed by memory instead of being reserved only.
+func burnMemWithMmap(ctx context.Context, memPercent, memReserve, memRate int, mode string, hugepageSize int64, includeBufferCache bool) {
+	if memRate <= 0 {
+		memRate = 100
+	}
+	var regions [][]byte
+	var allocated int64
+	tick := time.Tick(time.Second)
+	for range tick {
+		var size int64
+		if mode == ModeHugetlb {
+			size = hugetlbFillSize(ctx, memPercent, memReserve, memRate, hugepageSize)
+		} else {
+			_, expectMem, err := calculateMemSize(ctx, "ram", memPercent, memReserve, includeBufferCache)
+			if err != nil {
+				log.Fatalf(ctx, "calculate memsize err, %v", err)
+			}
+			size = min(expectMem, int64(memRate)) * 1024 * 1024
+		}
+		if size <= 0 {
+			continue
+		}
+		region, err := mmapRegion(mode, size, hugepageSize)
+		if err != nil {
+			// the pool or the memory is exhausted, which is what the experiment is for
+			log.Warnf(ctx, "mmap %d bytes by %s mode failed, %v", size, mode, err)
+			continue
+		}
+		regions = append(regions, region)
+		allocated += size
+		log.Infof(ctx, "burn mem by %s mode, allocated: %d bytes in %d regions", mode, allocated, len(regions))
+	}
+}
+
+// hugetlbFillSize returns the bytes to map from the hugepage pool, the mem-percent and reserve
+// flags are of the pool instead of the memory, and of the pools of the numa nodes if they are set.
+func hugetlbFillSize(ctx context.Context, memPercent, memReserve, memRate int, hugepageSize int64) int64 {
+	var nodes []int
+	if numa, ok := ctx.Value("numa").(*numaSpec); ok && numa != nil {
+		nodes = numa.nodes
+	}
+	total, free, err := hugepagePool(hugepageSize, nodes)
+	if err != nil {
+		log.Fatalf(ctx, "read the hugepage pool err, %v", err)
+	}
+	reserved := int64(memReserve) * 1024 * 1024 / hugepageSize
+	if memPercent != 0 {
+		reserved = total * int64(100-memPercent) / 100
+	}
+	pages := min(free-reserved, max(int64(memRate)*1024*1024/hugepageSize, 1))
+	log.Debugf(ctx, "hugepage pool total: %d, free: %d, reserved: %d, fill pages: %d", total, free, reserved, pages)
+	return pages * hugepageSize
+}
+
+// mmapRegion maps a private anonymous region by the mode and writes a byte to every page
+func mmapRegion(mode string, size, hugepageSize int64) ([]byte, error) {
+	flags := unix.MAP_PRIVATE | unix.MAP_ANONYMOUS
+	pageSize := int64(os.Getpagesize())
+	if mode == ModeHugetlb {
+		// the log2 of the page size selects the pool
+		flags |= unix.MAP_HUGETLB | (bits.TrailingZeros64(uint64(hugepageSize)) << unix.MAP_HUGE_SHIFT)
+		pageSize = hugepageSize
+	}
+	region, err := unix.Mmap(-1, 0, int(size), unix.PROT_READ|unix.PROT_WRITE, flags)
+	if err != nil {
+		return nil, err
+	}
+	if mode == ModeTHP {
+		if err := unix.Madvise(region, unix.MADV_HUGEPAGE); err != nil {
+			unix.Munmap(region)
+			return nil, fmt.Errorf("madvise MADV_HUGEPAGE failed, %v", err)
+		}
+	}
+	for i := int64(0); i < size; i += pageSize {
+		region[i] = 1
+	}
+	return region, nil
+}
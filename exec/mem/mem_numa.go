@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+)
+
+const (
+	NumaPolicyBind       = "bind"
+	NumaPolicyPreferred  = "preferred"
+	NumaPolicyInterleave = "interleave"
+)
+
+// numaSpec is the numa nodes which the memory is allocated from, and the memory policy
+type numaSpec struct {
+	nodes  []int
+	policy string
+}
+
+// parseNumaSpec parses the numa-node and numa-policy flags, it returns nil if numa-node is not set
+func parseNumaSpec(ctx context.Context, flags map[string]string) (*numaSpec, *spec.Response) {
+	nodeStr := flags["numa-node"]
+	policy := flags["numa-policy"]
+	if nodeStr == "" {
+		if policy != "" {
+			log.Errorf(ctx, "numa-node is nil")
+			return nil, spec.ResponseFailWithFlags(spec.ParameterLess, "numa-node")
+		}
+		return nil, nil
+	}
+	items, err := util.ParseIntegerListToStringSlice("numa-node", nodeStr)
+	if err != nil {
+		log.Errorf(ctx, "`%s`: numa-node is illegal, %v", nodeStr, err)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "numa-node", nodeStr, "it must be a node list, for example 0 or 0-1")
+	}
+	numa := &numaSpec{policy: NumaPolicyBind}
+	for _, item := range items {
+		node, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || node < 0 {
+			log.Errorf(ctx, "`%s`: numa-node is illegal, it must be a node list", nodeStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "numa-node", nodeStr, "it must be a node list, for example 0 or 0-1")
+		}
+		numa.nodes = append(numa.nodes, node)
+	}
+	switch policy {
+	case "", NumaPolicyBind, NumaPolicyInterleave:
+	case NumaPolicyPreferred:
+		if len(numa.nodes) > 1 {
+			log.Errorf(ctx, "`%s`: numa-node is illegal, preferred policy accepts a single node", nodeStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "numa-node", nodeStr, "preferred policy accepts a single node")
+		}
+	default:
+		log.Errorf(ctx, "`%s`: numa-policy is illegal, it must be bind, preferred or interleave", policy)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "numa-policy", policy, "it must be bind, preferred or interleave")
+	}
+	if policy != "" {
+		numa.policy = policy
+	}
+	if _, _, _, err := nodeMemory(numa.nodes); err != nil {
+		log.Errorf(ctx, "`%s`: numa-node is invalid, %v", nodeStr, err)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterInvalid, "numa-node", nodeStr, err)
+	}
+	return numa, nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+	"golang.org/x/sys/unix"
+)
+
+// nodeSysPath is the sysfs directory of the numa nodes
+var nodeSysPath = "/sys/devices/system/node"
+
+// the memory policy modes of set_mempolicy(2), they are not defined by x/sys/unix
+const (
+	mpolDefault    = 0
+	mpolPreferred  = 1
+	mpolBind       = 2
+	mpolInterleave = 3
+)
+
+// maxNumaNodes is the number of bits of the node mask passed to the kernel
+const maxNumaNodes = 1024
+
+var numaPolicyModes = map[string]int{
+	NumaPolicyBind:       mpolBind,
+	NumaPolicyPreferred:  mpolPreferred,
+	NumaPolicyInterleave: mpolInterleave,
+}
+
+// nodeCpus returns the cpus of the numa node, an error is returned if the node does not exist
+func nodeCpus(node int) ([]int, error) {
+	content, err := os.ReadFile(filepath.Join(nodeSysPath, fmt.Sprintf("node%d", node), "cpulist"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("numa node %d does not exist", node)
+		}
+		return nil, err
+	}
+	items, err := util.ParseIntegerListToStringSlice("cpulist", strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	cpus := make([]int, len(items))
+	for i, item := range items {
+		if cpus[i], err = strconv.Atoi(item); err != nil {
+			return nil, err
+		}
+	}
+	return cpus, nil
+}
+
+// setMempolicy sets the memory policy of the calling thread, the pages it touches later are
+// allocated from the nodes by the mode.
+func setMempolicy(mode int, nodes []int) error {
+	var mask [maxNumaNodes / 64]uint64
+	for _, node := range nodes {
+		if node < 0 || node >= maxNumaNodes {
+			return fmt.Errorf("numa node %d is out of range", node)
+		}
+		mask[node/64] |= 1 << (node % 64)
+	}
+	var maskPtr uintptr
+	if len(nodes) > 0 {
+		maskPtr = uintptr(unsafe.Pointer(&mask[0]))
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_SET_MEMPOLICY, uintptr(mode), maskPtr, maxNumaNodes); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// bindNumaNode runs the calling thread on the cpus of the node and allocates its memory from
+// the node, the goroutine must be locked to the thread.
+func bindNumaNode(node int) error {
+	cpus, err := nodeCpus(node)
+	if err != nil {
+		return err
+	}
+	if len(cpus) == 0 {
+		return fmt.Errorf("numa node %d has no cpu", node)
+	}
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		return fmt.Errorf("bind the thread to the cpus of numa node %d failed, %v", node, err)
+	}
+	if err := setMempolicy(mpolBind, []int{node}); err != nil {
+		return fmt.Errorf("bind the memory to numa node %d failed, %v", node, err)
+	}
+	return nil
+}
+
+// applyNumaPolicy sets the memory policy of the calling thread to the nodes of the numa spec,
+// the goroutine must be locked to the thread.
+func applyNumaPolicy(numa *numaSpec) error {
+	return setMempolicy(numaPolicyModes[numa.policy], numa.nodes)
+}
+
+// nodeMemory sums the MemTotal, MemFree and FilePages of the nodeN/meminfo of the nodes, in
+// bytes. The format of a line is: Node 0 MemTotal:  6157000 kB
+func nodeMemory(nodes []int) (int64, int64, int64, error) {
+	var total, free, filePages int64
+	for _, node := range nodes {
+		content, err := os.ReadFile(filepath.Join(nodeSysPath, fmt.Sprintf("node%d", node), "meminfo"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return 0, 0, 0, fmt.Errorf("numa node %d does not exist", node)
+			}
+			return 0, 0, 0, err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 5 {
+				continue
+			}
+			value, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			switch fields[2] {
+			case "MemTotal:":
+				total += value * 1024
+			case "MemFree:":
+				free += value * 1024
+			case "FilePages:":
+				filePages += value * 1024
+			}
+		}
+	}
+	return total, free, filePages, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseNumaSpec(t *testing.T) {
+	nodeSysPath = t.TempDir()
+	defer func() { nodeSysPath = "/sys/devices/system/node" }()
+	for node, meminfo := range map[string]string{
+		"node0": "Node 0 MemTotal:        4096 kB\nNode 0 MemFree:         1024 kB\nNode 0 FilePages:        512 kB\n",
+		"node1": "Node 1 MemTotal:        2048 kB\nNode 1 MemFree:         2048 kB\nNode 1 FilePages:          0 kB\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(nodeSysPath, node), 0o755); err != nil {
+			t.Fatalf("create %s failed, %v", node, err)
+		}
+		if err := os.WriteFile(filepath.Join(nodeSysPath, node, "meminfo"), []byte(meminfo), 0o644); err != nil {
+			t.Fatalf("write meminfo of %s failed, %v", node, err)
+		}
+	}
+
+	numa, resp := parseNumaSpec(context.Background(), map[string]string{"numa-node": "0-1", "numa-policy": "interleave"})
+	if resp != nil {
+		t.Fatalf("parse numa spec failed, %s", resp.Err)
+	}
+	if numa.policy != NumaPolicyInterleave || len(numa.nodes) != 2 {
+		t.Errorf("unexpected numa spec: %+v", numa)
+	}
+	total, free, filePages, err := nodeMemory(numa.nodes)
+	if err != nil {
+		t.Fatalf("read the node memory failed, %v", err)
+	}
+	if total != 6144*1024 || free != 3072*1024 || filePages != 512*1024 {
+		t.Errorf("unexpected node memory, total: %d, free: %d, file pages: %d", total, free, filePages)
+	}
+
+	if numa, resp := parseNumaSpec(context.Background(), map[string]string{}); numa != nil || resp != nil {
+		t.Errorf("unexpected numa spec without numa-node: %+v", numa)
+	}
+	for _, flags := range []map[string]string{
+		{"numa-policy": "bind"},
+		{"numa-node": "0-1", "numa-policy": "preferred"},
+		{"numa-node": "0", "numa-policy": "local"},
+		{"numa-node": "a"},
+		{"numa-node": "2"},
+	} {
+		if _, resp := parseNumaSpec(context.Background(), flags); resp == nil {
+			t.Errorf("expected error of the flags: %v", flags)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const OOMMemBin = "chaos_oommem"
+
+// defaultOOMWait is the seconds to wait for the oom kill
+const defaultOOMWait = 30
+
+type MemOOMActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemOOMActionCommand() spec.ExpActionCommandSpec {
+	return &MemOOMActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "pid",
+					Desc: "The pid of the process whose cgroup is out of memory, the pid is in the host pid namespace",
+				},
+				&spec.ExpFlag{
+					Name: "process",
+					Desc: "The name of the process whose cgroup is out of memory, the first matched process is used",
+				},
+			},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "victim-pids",
+					Desc: "The pids of the processes in the cgroup to kill, split by comma, their oom_score_adj is set to 1000 before the oom",
+				},
+				&spec.ExpFlag{
+					Name: "wait",
+					Desc: "The seconds to wait for the oom kill, default value is 30",
+				},
+			},
+			ActionExecutor: &memOOMExecutor{},
+			ActionExample: `
+# Trigger an oom kill in the cgroup of the process 1234
+blade create mem oom --pid 1234
+
+# Trigger an oom kill of the process 1235 in the cgroup of the nginx process
+blade create mem oom --process nginx --victim-pids 1235`,
+			ActionPrograms:   []string{OOMMemBin},
+			ActionCategories: []string{category.SystemMem},
+		},
+	}
+}
+
+func (*MemOOMActionCommand) Name() string {
+	return "oom"
+}
+
+func (*MemOOMActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemOOMActionCommand) ShortDesc() string {
+	return "Trigger an oom kill in a process cgroup"
+}
+
+func (o *MemOOMActionCommand) LongDesc() string {
+	if o.ActionLongDesc != "" {
+		return o.ActionLongDesc
+	}
+	return "Move the burner into the memory cgroup of the target process and allocate until the oom killer kills a process of the cgroup, " +
+		"the burner is protected by oom_score_adj -1000 and the victims are preferred by oom_score_adj 1000. The oom_kill counter of " +
+		"memory.events of cgroup v2 or memory.oom_control of cgroup v1 is watched and the killed processes are reported. The cgroup must have a memory limit"
+}
+
+type memOOMExecutor struct {
+	channel spec.Channel
+}
+
+func (*memOOMExecutor) Name() string {
+	return "oom"
+}
+
+func (oe *memOOMExecutor) SetChannel(channel spec.Channel) {
+	oe.channel = channel
+}
+
+// oomResult is the oom_kill counter of the cgroup before and after the oom, and the killed processes
+type oomResult struct {
+	before int64
+	after  int64
+	killed []int
+}
+
+func (oe *memOOMExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if oe.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		// the oom is over when the experiment returns, the oom_score_adj of the victims is restored then
+		return spec.Success()
+	}
+
+	wait := defaultOOMWait
+	if waitStr := model.ActionFlags["wait"]; waitStr != "" {
+		var err error
+		if wait, err = strconv.Atoi(waitStr); err != nil || wait <= 0 {
+			log.Errorf(ctx, "`%s`: wait is illegal, it must be a positive integer", waitStr)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, "wait", waitStr, "it must be a positive integer")
+		}
+	}
+	var victims []int
+	if victimPids := model.ActionFlags["victim-pids"]; victimPids != "" {
+		for _, pidStr := range strings.Split(victimPids, ",") {
+			pid, err := strconv.Atoi(strings.TrimSpace(pidStr))
+			if err != nil || pid <= 0 {
+				log.Errorf(ctx, "`%s`: victim-pids is illegal, it must be positive integers split by comma", victimPids)
+				return spec.ResponseFailWithFlags(spec.ParameterIllegal, "victim-pids", victimPids, "it must be positive integers split by comma")
+			}
+			victims = append(victims, pid)
+		}
+	}
+	pid, resp := exec.TargetPid(ctx, oe.channel, model)
+	if resp != nil {
+		return resp
+	}
+
+	result, err := oomCgroup(ctx, model.ActionFlags["cgroup-root"], pid, victims, time.Duration(wait)*time.Second)
+	if err != nil {
+		log.Errorf(ctx, "trigger the oom in the cgroup of pid %d failed, %v", pid, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("trigger the oom in the cgroup of pid %d failed, %v", pid, err))
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("oom_kill increased from %d to %d, killed pids: %v", result.before, result.after, result.killed))
+}
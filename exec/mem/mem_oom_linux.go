@@ -0,0 +1,202 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/containerd/cgroups"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	cgroupsv2 "github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+// oomChunk is the bytes allocated between two checks of the oom_kill counter
+const oomChunk = 1024 * 1024
+
+// oomCgroup moves the burner into the memory cgroup of the pid and allocates until the oom_kill
+// counter of the cgroup increases. The burner is protected by oom_score_adj -1000 and the victims
+// are preferred by 1000, the victims which survive get their original oom_score_adj back.
+func oomCgroup(ctx context.Context, cgroupRoot string, pid int, victims []int, wait time.Duration) (*oomResult, error) {
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	var cgroupPath, eventsFile string
+	if cgroupsv2.DetectCGroupVersion(ctx, cgroupRoot) == cgroupsv2.CGroupV2 {
+		var err error
+		if cgroupPath, err = cgroupsv2.FindCGroupV2Path(ctx, strconv.Itoa(pid), cgroupRoot); err != nil {
+			return nil, err
+		}
+		if cgroupPath == "" {
+			return nil, fmt.Errorf("cgroup v2 path of pid %d not found", pid)
+		}
+		limit, defined, err := cgroupsv2.NewCGroupV2Impl(cgroupPath).MemoryLimit()
+		if err != nil {
+			return nil, err
+		}
+		if !defined || limit == 0 {
+			return nil, fmt.Errorf("cgroup %s has no memory limit, lower it by mem pressure --hard-limit first", cgroupPath)
+		}
+		eventsFile = filepath.Join(cgroupPath, "memory.events")
+	} else {
+		memoryPath, err := exec.PidPath(pid)(cgroups.Memory)
+		if err != nil {
+			return nil, err
+		}
+		cgroupPath = filepath.Join(cgroupRoot, string(cgroups.Memory), memoryPath)
+		content, err := os.ReadFile(filepath.Join(cgroupPath, "memory.limit_in_bytes"))
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if limit >= PageCounterMax {
+			return nil, fmt.Errorf("cgroup %s has no memory limit, lower it by mem pressure --hard-limit first", cgroupPath)
+		}
+		eventsFile = filepath.Join(cgroupPath, "memory.oom_control")
+		// the allocation hangs instead of being killed if the oom killer is disabled
+		if disabled, err := readKeyedInt(eventsFile, "oom_kill_disable"); err != nil {
+			return nil, err
+		} else if disabled != 0 {
+			return nil, fmt.Errorf("oom killer of cgroup %s is disabled", cgroupPath)
+		}
+	}
+
+	result := &oomResult{}
+	var err error
+	if result.before, err = readKeyedInt(eventsFile, "oom_kill"); err != nil {
+		return nil, err
+	}
+	procs, err := cgroupProcs(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	killable := false
+	for _, proc := range procs {
+		if score, err := os.ReadFile(fmt.Sprintf(processOOMScoreAdj, proc)); err == nil && strings.TrimSpace(string(score)) != oomMinScore {
+			killable = true
+		}
+	}
+	if !killable {
+		return nil, fmt.Errorf("no process of cgroup %s can be killed by the oom killer", cgroupPath)
+	}
+	for _, victim := range victims {
+		if !slices.Contains(procs, victim) {
+			return nil, fmt.Errorf("victim %d is not in cgroup %s", victim, cgroupPath)
+		}
+	}
+	// lowering oom_score_adj needs CAP_SYS_RESOURCE, the burner is as likely to be killed as the victims without it
+	if err := os.WriteFile(selfOOMScoreAdj, []byte(oomMinScore), 0o644); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("protect the burner from the oom killer failed, %v", err)
+	}
+	for _, victim := range victims {
+		scoreAdjFile := fmt.Sprintf(processOOMScoreAdj, victim)
+		original, err := os.ReadFile(scoreAdjFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(scoreAdjFile, []byte(oomMaxScore), 0o644); err != nil { //nolint:gosec
+			return nil, fmt.Errorf("write %s to %s failed, %v", oomMaxScore, scoreAdjFile, err)
+		}
+		defer func() {
+			// the killed victims have nothing to restore
+			if err := os.WriteFile(scoreAdjFile, original, 0o644); err == nil { //nolint:gosec
+				log.Infof(ctx, "restore %s to %s", scoreAdjFile, strings.TrimSpace(string(original)))
+			}
+		}()
+	}
+
+	if err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("move the burner into cgroup %s failed, %v", cgroupPath, err)
+	}
+	log.Infof(ctx, "burner moved into cgroup %s, oom_kill: %d, victims: %v", cgroupPath, result.before, victims)
+
+	var chunks [][]byte
+	deadline := time.Now().Add(wait)
+	for result.after = result.before; result.after == result.before; {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("no oom kill in cgroup %s after %d MB allocated in %s", cgroupPath, len(chunks)*oomChunk/1024/1024, wait)
+		}
+		chunk := make([]byte, oomChunk)
+		for i := 0; i < len(chunk); i += os.Getpagesize() {
+			chunk[i] = 1
+		}
+		chunks = append(chunks, chunk)
+		if result.after, err = readKeyedInt(eventsFile, "oom_kill"); err != nil {
+			return nil, err
+		}
+	}
+	log.Infof(ctx, "oom_kill of cgroup %s increased to %d after %d MB allocated", cgroupPath, result.after, len(chunks)*oomChunk/1024/1024)
+
+	// the killed processes leave the cgroup asynchronously
+	time.Sleep(100 * time.Millisecond)
+	remained, err := cgroupProcs(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, proc := range procs {
+		if !slices.Contains(remained, proc) {
+			result.killed = append(result.killed, proc)
+		}
+	}
+	return result, nil
+}
+
+// readKeyedInt reads the value of the key from a flat keyed file, for example memory.events
+func readKeyedInt(file, key string) (int64, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("%s not found in %s", key, file)
+}
+
+// cgroupProcs returns the pids in cgroup.procs of the cgroup
+func cgroupProcs(cgroupPath string) ([]int, error) {
+	content, err := os.ReadFile(filepath.Join(cgroupPath, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, field := range strings.Fields(string(content)) {
+		pid, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
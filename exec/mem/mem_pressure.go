@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const PressureMemBin = "chaos_pressuremem"
+
+type MemPressureActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemPressureActionCommand() spec.ExpActionCommandSpec {
+	return &MemPressureActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "pid",
+					Desc: "The pid of the process whose cgroup is pressed, the pid is in the host pid namespace",
+				},
+				&spec.ExpFlag{
+					Name: "process",
+					Desc: "The name of the process whose cgroup is pressed, the first matched process is used",
+				},
+			},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name:   "hard-limit",
+					Desc:   "Lower memory.max of cgroup v2 or memory.limit_in_bytes of cgroup v1 instead of memory.high or memory.soft_limit_in_bytes",
+					NoArgs: true,
+				},
+			},
+			ActionExecutor: &memPressureExecutor{},
+			ActionExample: `
+# Lower memory.high of the cgroup of the process 1234 to 50% of its memory limit
+blade create mem pressure --pid 1234 --mem-percent 50
+
+# Lower the hard limit of the cgroup of the java process to 80% of its memory limit
+blade create mem pressure --process java --mem-percent 80 --hard-limit`,
+			ActionPrograms:   []string{PressureMemBin},
+			ActionCategories: []string{category.SystemMem},
+		},
+	}
+}
+
+func (*MemPressureActionCommand) Name() string {
+	return "pressure"
+}
+
+func (*MemPressureActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemPressureActionCommand) ShortDesc() string {
+	return "Lower the memory limit of a process cgroup"
+}
+
+func (p *MemPressureActionCommand) LongDesc() string {
+	if p.ActionLongDesc != "" {
+		return p.ActionLongDesc
+	}
+	return "Lower memory.high of cgroup v2 or memory.soft_limit_in_bytes of cgroup v1 of the cgroup which the target process belongs to, " +
+		"to induce reclaim and throttling inside the container without allocating any memory. The mem-percent flag is the percent of " +
+		"the memory limit of the cgroup, or of the host memory if it is unlimited. The original value is restored on destroy"
+}
+
+type memPressureExecutor struct {
+	channel spec.Channel
+}
+
+func (*memPressureExecutor) Name() string {
+	return "pressure"
+}
+
+func (pe *memPressureExecutor) SetChannel(channel spec.Channel) {
+	pe.channel = channel
+}
+
+func (pe *memPressureExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if pe.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		if err := exec.RestoreState(ctx, PressureMemBin, uid); err != nil {
+			log.Errorf(ctx, "restore the memory limit failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("restore the memory limit failed, %v", err))
+		}
+		return spec.Success()
+	}
+
+	memPercentStr := model.ActionFlags["mem-percent"]
+	if memPercentStr == "" {
+		log.Errorf(ctx, "mem-percent is nil")
+		return spec.ResponseFailWithFlags(spec.ParameterLess, "mem-percent")
+	}
+	memPercent, err := strconv.Atoi(memPercentStr)
+	if err != nil || memPercent <= 0 || memPercent >= 100 {
+		log.Errorf(ctx, "`%s`: mem-percent is illegal, it must be a positive integer and less than 100", memPercentStr)
+		return spec.ResponseFailWithFlags(spec.ParameterIllegal, "mem-percent", memPercentStr, "it must be a positive integer and less than 100")
+	}
+
+	pid, resp := exec.TargetPid(ctx, pe.channel, model)
+	if resp != nil {
+		return resp
+	}
+	if exec.StateExists(PressureMemBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, PressureMemBin+"."+uid)
+	}
+	limit, err := pressureCgroup(ctx, model.ActionFlags["cgroup-root"], uid, pid, memPercent, model.ActionFlags["hard-limit"] == "true")
+	if err != nil {
+		log.Errorf(ctx, "lower the memory limit of the cgroup of pid %d failed, %v", pid, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("lower the memory limit of the cgroup of pid %d failed, %v", pid, err))
+	}
+	return spec.ReturnSuccess(limit)
+}
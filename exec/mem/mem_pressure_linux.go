@@ -0,0 +1,97 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/channel"
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/containerd/cgroups"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	cgroupsv2 "github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+// pressureCgroup lowers the memory limit of the cgroup of the pid to memPercent of its total,
+// which is the hard limit of the cgroup, or the host memory if it is unlimited. The original
+// value is saved to the state of uid.
+func pressureCgroup(ctx context.Context, cgroupRoot, uid string, pid, memPercent int, hardLimit bool) (string, error) {
+	if cgroupRoot == "" {
+		cgroupRoot = "/sys/fs/cgroup"
+	}
+	ctx = context.WithValue(ctx, channel.NSTargetFlagName, strconv.Itoa(pid))
+	ctx = context.WithValue(ctx, "cgroup-root", cgroupRoot)
+
+	var limitFile string
+	var total, available int64
+	var err error
+	if cgroupsv2.DetectCGroupVersion(ctx, cgroupRoot) == cgroupsv2.CGroupV2 {
+		var cgroupPath string
+		if cgroupPath, err = cgroupsv2.FindCGroupV2Path(ctx, strconv.Itoa(pid), cgroupRoot); err != nil {
+			return "", err
+		}
+		if cgroupPath == "" {
+			return "", fmt.Errorf("cgroup v2 path of pid %d not found", pid)
+		}
+		limitFile = filepath.Join(cgroupPath, "memory.high")
+		if hardLimit {
+			limitFile = filepath.Join(cgroupPath, cgroupsv2.CGroupV2MemoryLimitFile)
+		}
+		total, available, err = getAvailableAndTotalV2(ctx, "cache", true)
+	} else {
+		var memoryPath string
+		if memoryPath, err = exec.PidPath(pid)(cgroups.Memory); err != nil {
+			return "", err
+		}
+		limitFile = filepath.Join(cgroupRoot, string(cgroups.Memory), memoryPath, "memory.soft_limit_in_bytes")
+		if hardLimit {
+			limitFile = filepath.Join(cgroupRoot, string(cgroups.Memory), memoryPath, "memory.limit_in_bytes")
+		}
+		total, available, err = getAvailableAndTotalV1(ctx, "cache", true, pid, cgroupRoot)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(limitFile)
+	if err != nil {
+		return "", err
+	}
+	original := strings.TrimSpace(string(content))
+	limit := total * int64(memPercent) / 100
+	if current, err := strconv.ParseInt(original, 10, 64); err == nil && current <= limit {
+		return "", fmt.Errorf("%s is %s, it is not higher than %d", limitFile, original, limit)
+	}
+	value := strconv.FormatInt(limit, 10)
+	if err := exec.SaveState(PressureMemBin, uid, []exec.FileValue{{Path: limitFile, Value: original}}); err != nil {
+		return "", fmt.Errorf("save the original memory limit failed, %v", err)
+	}
+	if err := os.WriteFile(limitFile, []byte(value), 0o644); err != nil { //nolint:gosec
+		if restoreErr := exec.RestoreState(ctx, PressureMemBin, uid); restoreErr != nil {
+			log.Warnf(ctx, "remove the state of %s failed, %v", uid, restoreErr)
+		}
+		return "", fmt.Errorf("write %s to %s failed, %v", value, limitFile, err)
+	}
+	log.Infof(ctx, "memory limit of %s is lowered from %s to %s, total: %d, usage: %d", limitFile, original, value, total, total-available)
+	return value, nil
+}
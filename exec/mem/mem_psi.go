@@ -0,0 +1,148 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+)
+
+const (
+	PSISome = "some"
+	PSIFull = "full"
+)
+
+// procPressureMemory is the memory pressure stall information of the host
+var procPressureMemory = "/proc/pressure/memory"
+
+// psiTarget is the pressure stall percent of psi mode, and the file it is read from
+type psiTarget struct {
+	percent float64
+	kind    string
+	file    string
+}
+
+// parsePSITarget parses the psi flags and resolves the pressure file, which is memory.pressure
+// of the cgroup of the target process on cgroup v2, or /proc/pressure/memory.
+func parsePSITarget(ctx context.Context, flags map[string]string) (*psiTarget, *spec.Response) {
+	percentStr := flags["psi-target"]
+	if percentStr == "" {
+		log.Errorf(ctx, "psi-target is nil")
+		return nil, spec.ResponseFailWithFlags(spec.ParameterLess, "psi-target")
+	}
+	percent, err := strconv.ParseFloat(percentStr, 64)
+	if err != nil || percent <= 0 || percent >= 100 {
+		log.Errorf(ctx, "`%s`: psi-target is illegal, it must be a positive number and less than 100", percentStr)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "psi-target", percentStr, "it must be a positive number and less than 100")
+	}
+	psi := &psiTarget{percent: percent, kind: PSISome}
+	if kind := flags["psi-type"]; kind != "" {
+		if kind != PSISome && kind != PSIFull {
+			log.Errorf(ctx, "`%s`: psi-type is illegal, it must be some or full", kind)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "psi-type", kind, "it must be some or full")
+		}
+		psi.kind = kind
+	}
+	if psi.file, err = pressureFile(ctx); err != nil {
+		log.Errorf(ctx, "find the memory pressure file failed, %v", err)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterInvalid, "psi-target", percentStr, err)
+	}
+	if _, err := readPressureTotal(psi.file, psi.kind); err != nil {
+		log.Errorf(ctx, "read %s failed, %v", psi.file, err)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterInvalid, "psi-target", percentStr,
+			fmt.Sprintf("psi is not available, it needs a kernel with CONFIG_PSI and psi enabled, %v", err))
+	}
+	return psi, nil
+}
+
+// burnMemWithPressure grows the allocation by rate MB every second while the stall of the last
+// second is below the target, and shrinks it while the stall is above the target. The stall is
+// the increase of the total stall time, so the avg10 follows it without its 10 seconds delay. All
+// of the allocation is touched every second, so that it keeps competing with the other pages.
+func burnMemWithPressure(ctx context.Context, memRate int, psi *psiTarget) {
+	if memRate <= 0 {
+		memRate = 100
+	}
+	log.Infof(ctx, "burn mem with %s %s avg10=%.2f", psi.file, psi.kind, psi.percent)
+	lastTotal, err := readPressureTotal(psi.file, psi.kind)
+	if err != nil {
+		log.Fatalf(ctx, "read %s failed, %v", psi.file, err)
+	}
+	lastTime := time.Now()
+	var chunks [][]byte
+	for range time.Tick(time.Second) {
+		total, err := readPressureTotal(psi.file, psi.kind)
+		if err != nil {
+			log.Fatalf(ctx, "read %s failed, %v", psi.file, err)
+		}
+		now := time.Now()
+		// the total is the stall time in microseconds
+		stall := float64(total-lastTotal) / float64(now.Sub(lastTime).Microseconds()) * 100
+		lastTotal, lastTime = total, now
+
+		if stall < psi.percent {
+			for i := 0; i < memRate; i++ {
+				chunks = append(chunks, make([]byte, 1024*1024))
+			}
+		} else if release := min(memRate, len(chunks)); release > 0 {
+			chunks = releaseChunks(chunks, release)
+			debug.FreeOSMemory()
+		}
+		for _, chunk := range chunks {
+			for i := 0; i < len(chunk); i += os.Getpagesize() {
+				chunk[i]++
+			}
+		}
+		log.Debugf(ctx, "memory pressure stall: %.2f, target: %.2f, allocated: %dMB", stall, psi.percent, len(chunks))
+	}
+}
+
+// releaseChunks drops the last release chunks, the tail of the backing array is cleared, so that
+// the chunks are not reachable by it and can be freed
+func releaseChunks(chunks [][]byte, release int) [][]byte {
+	clear(chunks[len(chunks)-release:])
+	return chunks[:len(chunks)-release]
+}
+
+// readPressureTotal reads the total stall time of the kind from the pressure file, the format is:
+// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressureTotal(file, kind string) (int64, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != kind {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if total, ok := strings.CutPrefix(field, "total="); ok {
+				return strconv.ParseInt(total, 10, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("%s total not found in %s", kind, file)
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/shirou/gopsutil/process"
+)
+
+// rssReportInterval is the seconds between two reports of the rss of ram mode
+const rssReportInterval = 10
+
+// residentSpec keeps the memory of ram mode resident, touchInterval is 0 if it is not touched
+type residentSpec struct {
+	lock          bool
+	touchInterval int
+}
+
+// parseResidentSpec parses the lock and touch-interval flags, which are of ram mode only. The
+// expected size of the burn is checked against RLIMIT_MEMLOCK if it is locked.
+func parseResidentSpec(ctx context.Context, flags map[string]string, burnMemMode string, memPercent, memReserve int,
+	includeBufferCache bool,
+) (*residentSpec, *spec.Response) {
+	resident := &residentSpec{lock: flags["lock"] == "true"}
+	if touchIntervalStr := flags["touch-interval"]; touchIntervalStr != "" {
+		touchInterval, err := strconv.Atoi(touchIntervalStr)
+		if err != nil || touchInterval <= 0 {
+			log.Errorf(ctx, "`%s`: touch-interval is illegal, it must be a positive integer", touchIntervalStr)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "touch-interval", touchIntervalStr, "it must be a positive integer")
+		}
+		resident.touchInterval = touchInterval
+	}
+	if !resident.lock && resident.touchInterval == 0 {
+		return resident, nil
+	}
+	if burnMemMode != "" && burnMemMode != "ram" {
+		log.Errorf(ctx, "`%s`: lock and touch-interval are of ram mode only", burnMemMode)
+		return nil, spec.ResponseFailWithFlags(spec.ParameterIllegal, "mode", burnMemMode, "lock and touch-interval are of ram mode only")
+	}
+	if resident.lock {
+		_, expectMem, err := calculateMemSize(ctx, "ram", memPercent, memReserve, includeBufferCache)
+		if err != nil {
+			log.Errorf(ctx, "calculate memsize err, %v", err)
+			return nil, spec.ReturnFail(spec.OsCmdExecFailed, err.Error())
+		}
+		if err := checkMemLock(expectMem * 1024 * 1024); err != nil {
+			log.Errorf(ctx, "the memory can not be locked, %v", err)
+			return nil, spec.ResponseFailWithFlags(spec.ParameterInvalid, "lock", "true", err)
+		}
+	}
+	return resident, nil
+}
+
+// growBlocks appends n blocks to buf, the new blocks are locked if lock is set. If the append moves
+// the blocks, the old ones are unlocked and the moved ones are locked, so that only the blocks are
+// locked rather than the runtime and the freed memory of the burner.
+func growBlocks(buf []Block, n int, lock bool) ([]Block, error) {
+	grown := append(buf, make([]Block, n)...)
+	if !lock {
+		return grown, nil
+	}
+	if len(buf) > 0 && unsafe.SliceData(grown) != unsafe.SliceData(buf) {
+		if err := unlockBlocks(buf); err != nil {
+			return grown, err
+		}
+		return grown, lockBlocks(grown)
+	}
+	return grown, lockBlocks(grown[len(buf):])
+}
+
+// blocksBytes returns the memory of the blocks as bytes
+func blocksBytes(blocks []Block) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(blocks))), len(blocks)*int(unsafe.Sizeof(Block{})))
+}
+
+// touchBlocks writes every page of the blocks, the swapped or compressed pages are faulted back
+func touchBlocks(cache map[int][]Block) {
+	step := os.Getpagesize() / int(unsafe.Sizeof(int32(0)))
+	for _, blocks := range cache {
+		for i := range blocks {
+			for j := 0; j < len(blocks[i]); j += step {
+				blocks[i][j]++
+			}
+		}
+	}
+}
+
+// reportRSS logs the rss and swap of the burner compared with the size of the blocks, a rss
+// lower than the blocks means that they are swapped or compressed.
+func reportRSS(ctx context.Context, cache map[int][]Block) {
+	var expected uint64
+	for _, blocks := range cache {
+		expected += uint64(len(blocks)) * uint64(unsafe.Sizeof(Block{}))
+	}
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		log.Warnf(ctx, "get the burner process failed, %v", err)
+		return
+	}
+	info, err := proc.MemoryInfo()
+	if err != nil {
+		log.Warnf(ctx, "get the memory of the burner failed, %v", err)
+		return
+	}
+	log.Infof(ctx, "burn mem rss: %dMB, swap: %dMB, expected: %dMB", info.RSS/1024/1024, info.Swap/1024/1024, expected/1024/1024)
+}
@@ -0,0 +1,379 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	"github.com/chaosblade-io/chaosblade-exec-os/exec/category"
+)
+
+const (
+	SwapMemBin    = "chaos_swapmem"
+	SwapoffMemBin = "chaos_swapoffmem"
+)
+
+const (
+	// defaultSwapPercent is the percent of the swap to fill
+	defaultSwapPercent = 50
+	// defaultResident is the memory limit of the dedicated cgroup of the swap filler, in MB
+	defaultResident = 64
+	// defaultSwapRate is the MB allocated every second
+	defaultSwapRate = 100
+)
+
+// procSwaps is the swap devices of the host
+var procSwaps = "/proc/swaps"
+
+// swapDevice is a line of /proc/swaps, the sizes are in KB
+type swapDevice struct {
+	path     string
+	kind     string
+	size     int64
+	used     int64
+	priority int
+}
+
+type MemSwapActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemSwapActionCommand() spec.ExpActionCommandSpec {
+	return &MemSwapActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "swap-percent",
+					Desc: "percent of the total swap to fill (1-100), default value is 50",
+				},
+				&spec.ExpFlag{
+					Name: "resident",
+					Desc: "memory limit of the dedicated cgroup of the filler, the memory above it is swapped out, unit is MB, default value is 64",
+				},
+			},
+			ActionExecutor: &memSwapExecutor{},
+			ActionExample: `
+# Fill 50% of the swap
+blade create mem swap
+
+# Fill 80% of the swap at 200MB/s
+blade create mem swap --swap-percent 80 --rate 200`,
+			ActionPrograms:    []string{SwapMemBin},
+			ActionCategories:  []string{category.SystemMem},
+			ActionProcessHang: true,
+		},
+	}
+}
+
+func (*MemSwapActionCommand) Name() string {
+	return "swap"
+}
+
+func (*MemSwapActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemSwapActionCommand) ShortDesc() string {
+	return "Fill the swap"
+}
+
+func (s *MemSwapActionCommand) LongDesc() string {
+	if s.ActionLongDesc != "" {
+		return s.ActionLongDesc
+	}
+	return "Move the filler into a dedicated memory cgroup limited to the resident flag and allocate until the used swap of /proc/swaps " +
+		"reaches the swap-percent, the memory above the limit is swapped out by the kernel. The rate flag is the MB allocated every second, " +
+		"default value is 100. The cgroup is removed on destroy"
+}
+
+type memSwapExecutor struct {
+	channel spec.Channel
+}
+
+func (*memSwapExecutor) Name() string {
+	return "swap"
+}
+
+func (se *memSwapExecutor) SetChannel(channel spec.Channel) {
+	se.channel = channel
+}
+
+func (se *memSwapExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if se.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		ctx = context.WithValue(ctx, "bin", SwapMemBin)
+		response := exec.Destroy(ctx, se.channel, "mem swap")
+		// remove the dedicated cgroup left by the killed filler
+		if uid == spec.UnknownUid {
+			uid = ""
+		}
+		removeSwapCgroup(ctx, uid)
+		return response
+	}
+
+	swapPercent, resident, rate := defaultSwapPercent, defaultResident, defaultSwapRate
+	for _, flag := range []struct {
+		name  string
+		value *int
+		max   int
+	}{{"swap-percent", &swapPercent, 100}, {"resident", &resident, 0}, {"rate", &rate, 0}} {
+		valueStr := model.ActionFlags[flag.name]
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value <= 0 || (flag.max > 0 && value > flag.max) {
+			log.Errorf(ctx, "`%s`: %s is illegal, it must be a positive integer", valueStr, flag.name)
+			return spec.ResponseFailWithFlags(spec.ParameterIllegal, flag.name, valueStr, "it must be a positive integer")
+		}
+		*flag.value = value
+	}
+	devices, err := readSwaps()
+	if err != nil {
+		log.Errorf(ctx, "read %s failed, %v", procSwaps, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read %s failed, %v", procSwaps, err))
+	}
+	if len(devices) == 0 {
+		log.Errorf(ctx, "no swap device in %s", procSwaps)
+		return spec.ResponseFailWithFlags(spec.ParameterInvalid, "swap-percent", swapPercent, "there is no swap device")
+	}
+	if err := enterSwapCgroup(ctx, model.ActionFlags["cgroup-root"], uid, int64(resident)*1024*1024); err != nil {
+		log.Errorf(ctx, "create the swap cgroup failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("create the swap cgroup failed, %v", err))
+	}
+	fillSwap(ctx, swapPercent, rate)
+	return spec.Success()
+}
+
+// fillSwap allocates up to rate MB every second while the used swap is below the swapPercent.
+// The pages are filled with pseudo random words, so that they can be neither merged as
+// same-filled pages nor compressed by zswap.
+func fillSwap(ctx context.Context, swapPercent, rate int) {
+	var chunks [][]byte
+	seed := uint64(time.Now().UnixNano()) | 1
+	for range time.Tick(time.Second) {
+		devices, err := readSwaps()
+		if err != nil {
+			log.Fatalf(ctx, "read %s failed, %v", procSwaps, err)
+		}
+		var size, used int64
+		for _, device := range devices {
+			size += device.size
+			used += device.used
+		}
+		expect := (size*int64(swapPercent)/100 - used) / 1024
+		log.Debugf(ctx, "swap size: %dKB, used: %dKB, allocated: %dMB, expect: %dMB", size, used, len(chunks), expect)
+		for i := int64(0); i < expect && i < int64(rate); i++ {
+			chunk := make([]byte, 1024*1024)
+			for j := 0; j < len(chunk); j += 8 {
+				// xorshift
+				seed ^= seed << 13
+				seed ^= seed >> 7
+				seed ^= seed << 17
+				binary.LittleEndian.PutUint64(chunk[j:], seed)
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+}
+
+type MemSwapoffActionCommand struct {
+	spec.BaseExpActionCommandSpec
+}
+
+func NewMemSwapoffActionCommand() spec.ExpActionCommandSpec {
+	return &MemSwapoffActionCommand{
+		spec.BaseExpActionCommandSpec{
+			ActionMatchers: []spec.ExpFlagSpec{},
+			ActionFlags: []spec.ExpFlagSpec{
+				&spec.ExpFlag{
+					Name: "device",
+					Desc: "swap devices or files of /proc/swaps to swapoff, split by comma, all of them are swapoff if it is not set",
+				},
+			},
+			ActionExecutor: &memSwapoffExecutor{},
+			ActionExample: `
+# Swapoff all of the swap devices
+blade create mem swapoff
+
+# Swapoff the swap file /swapfile
+blade create mem swapoff --device /swapfile`,
+			ActionPrograms:   []string{SwapoffMemBin},
+			ActionCategories: []string{category.SystemMem},
+		},
+	}
+}
+
+func (*MemSwapoffActionCommand) Name() string {
+	return "swapoff"
+}
+
+func (*MemSwapoffActionCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*MemSwapoffActionCommand) ShortDesc() string {
+	return "Swapoff the swap devices"
+}
+
+func (s *MemSwapoffActionCommand) LongDesc() string {
+	if s.ActionLongDesc != "" {
+		return s.ActionLongDesc
+	}
+	return "Swapoff the swap devices of /proc/swaps, the swapped pages are read back into memory first, so it fails if the free memory is not enough. " +
+		"The devices are swapon with their original priorities on destroy"
+}
+
+type memSwapoffExecutor struct {
+	channel spec.Channel
+}
+
+func (*memSwapoffExecutor) Name() string {
+	return "swapoff"
+}
+
+func (se *memSwapoffExecutor) SetChannel(channel spec.Channel) {
+	se.channel = channel
+}
+
+func (se *memSwapoffExecutor) Exec(uid string, ctx context.Context, model *spec.ExpModel) *spec.Response {
+	if se.channel == nil {
+		log.Errorf(ctx, "%s", spec.ChannelNil.Msg)
+		return spec.ResponseFailWithFlags(spec.ChannelNil)
+	}
+	if _, ok := spec.IsDestroy(ctx); ok {
+		if err := exec.RestoreStateBy(ctx, SwapoffMemBin, uid, restoreSwap); err != nil {
+			log.Errorf(ctx, "swapon the devices failed, %v", err)
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("swapon the devices failed, %v", err))
+		}
+		return spec.Success()
+	}
+
+	devices, err := readSwaps()
+	if err != nil {
+		log.Errorf(ctx, "read %s failed, %v", procSwaps, err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("read %s failed, %v", procSwaps, err))
+	}
+	if deviceStr := model.ActionFlags["device"]; deviceStr != "" {
+		var selected []swapDevice
+		for _, path := range strings.Split(deviceStr, ",") {
+			path = strings.TrimSpace(path)
+			index := slices.IndexFunc(devices, func(device swapDevice) bool { return device.path == path })
+			if index < 0 {
+				log.Errorf(ctx, "`%s`: device is not in %s", path, procSwaps)
+				return spec.ResponseFailWithFlags(spec.ParameterInvalid, "device", path, "it is not in "+procSwaps)
+			}
+			selected = append(selected, devices[index])
+		}
+		devices = selected
+	}
+	if len(devices) == 0 {
+		return spec.ReturnSuccess("no swap device to swapoff")
+	}
+	if exec.StateExists(SwapoffMemBin, uid) {
+		return spec.ResponseFailWithFlags(spec.BackfileExists, SwapoffMemBin+"."+uid)
+	}
+
+	values := make([]exec.FileValue, len(devices))
+	for i, device := range devices {
+		values[i] = exec.FileValue{Path: device.path, Value: strconv.Itoa(device.priority)}
+	}
+	if err := exec.SaveState(SwapoffMemBin, uid, values); err != nil {
+		log.Errorf(ctx, "save the swap devices failed, %v", err)
+		return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("save the swap devices failed, %v", err))
+	}
+	for _, device := range devices {
+		if err := swapoff(device.path); err != nil {
+			log.Errorf(ctx, "swapoff %s failed, %v", device.path, err)
+			if restoreErr := exec.RestoreStateBy(ctx, SwapoffMemBin, uid, restoreSwap); restoreErr != nil {
+				log.Errorf(ctx, "swapon the devices failed, %v", restoreErr)
+			}
+			return spec.ReturnFail(spec.OsCmdExecFailed, fmt.Sprintf("swapoff %s failed, %v", device.path, err))
+		}
+		log.Infof(ctx, "swapoff %s, size: %dKB, used: %dKB", device.path, device.size, device.used)
+	}
+	return spec.ReturnSuccess(fmt.Sprintf("%d swap devices are swapoff", len(devices)))
+}
+
+// restoreSwap swapon the saved device with its priority, the device which is already swapon is skipped
+func restoreSwap(value exec.FileValue) error {
+	devices, err := readSwaps()
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(devices, func(device swapDevice) bool { return device.path == value.Path }) {
+		return nil
+	}
+	priority, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return err
+	}
+	return swapon(value.Path, priority)
+}
+
+func readSwaps() ([]swapDevice, error) {
+	file, err := os.Open(procSwaps)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseSwaps(bufio.NewScanner(file))
+}
+
+// parseSwaps parses the lines of /proc/swaps, the first line is the header:
+// Filename  Type  Size  Used  Priority
+func parseSwaps(scanner *bufio.Scanner) ([]swapDevice, error) {
+	var devices []swapDevice
+	for header := true; scanner.Scan(); header = false {
+		fields := strings.Fields(scanner.Text())
+		if header || len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("invalid swap line: %s", scanner.Text())
+		}
+		device := swapDevice{path: strings.ReplaceAll(fields[0], `\040`, " "), kind: fields[1]}
+		var err error
+		if device.size, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			return nil, err
+		}
+		if device.used, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+			return nil, err
+		}
+		if device.priority, err = strconv.Atoi(fields[4]); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, scanner.Err()
+}
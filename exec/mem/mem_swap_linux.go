@@ -0,0 +1,105 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"golang.org/x/sys/unix"
+
+	"github.com/chaosblade-io/chaosblade-exec-os/exec"
+	cgroupsv2 "github.com/chaosblade-io/chaosblade-exec-os/pkg/automaxprocs/cgroups"
+)
+
+const (
+	// swapCgroupPrefix is the name prefix of the dedicated cgroup of the swap filler, the uid is appended
+	swapCgroupPrefix = "chaos_swapmem_"
+	// swapFlagPrefer and swapFlagPrioMask are SWAP_FLAG_PREFER and SWAP_FLAG_PRIO_MASK of linux/swap.h
+	swapFlagPrefer   = 0x8000
+	swapFlagPrioMask = 0x7fff
+)
+
+// enterSwapCgroup creates the dedicated cgroup limited to resident bytes and moves the filler into
+// it, so the memory above the limit is swapped out by the kernel.
+func enterSwapCgroup(ctx context.Context, cgroupRoot, uid string, resident int64) error {
+	swapCgroup, err := exec.NewDedicatedCgroup(ctx, cgroupRoot, cgroupsv2.CGroupV2MemoryController,
+		swapCgroupPrefix+uid, procPid(), SwapMemBin, uid)
+	if err != nil {
+		return err
+	}
+	if inherited, ok := swapCgroup.InheritedLimit(cgroupsv2.CGroupV2MemoryLimitFile); ok {
+		if limit, err := strconv.ParseInt(inherited, 10, 64); err == nil {
+			resident = min(resident, limit)
+		}
+	}
+	limit := strconv.FormatInt(resident, 10)
+	if swapCgroup.Version == cgroupsv2.CGroupV2 {
+		err = swapCgroup.Write(cgroupsv2.CGroupV2MemoryLimitFile, limit)
+	} else {
+		err = swapCgroup.Write("memory.swappiness", "100")
+		if err == nil {
+			err = swapCgroup.Write("memory.limit_in_bytes", limit)
+		}
+	}
+	if err == nil {
+		err = swapCgroup.Enter(os.Getpid())
+	}
+	if err != nil {
+		swapCgroup.Remove(ctx)
+		return err
+	}
+	log.Infof(ctx, "filler moved into cgroup %s, memory limit: %s", swapCgroup.Path, limit)
+	return nil
+}
+
+// removeSwapCgroup removes the dedicated cgroups recorded by the killed fillers. If the uid is
+// empty, all of them are removed. The filler leaves its cgroup after its swapped pages are freed.
+func removeSwapCgroup(ctx context.Context, uid string) {
+	exec.RemoveDedicatedCgroups(ctx, SwapMemBin, uid, 5*time.Second)
+}
+
+// swapon enables the swap device, a negative priority is assigned by the kernel
+func swapon(path string, priority int) error {
+	pathPtr, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	flags := 0
+	if priority >= 0 {
+		flags = swapFlagPrefer | (priority & swapFlagPrioMask)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_SWAPON, uintptr(unsafe.Pointer(pathPtr)), uintptr(flags), 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func swapoff(path string) error {
+	pathPtr, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_SWAPOFF, uintptr(unsafe.Pointer(pathPtr)), 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+	"weak"
+)
+
+func TestParseHugepageSize(t *testing.T) {
+	tests := map[string]int64{
+		"2M":    2 << 20,
+		"1G":    1 << 30,
+		"2048k": 2 << 20,
+		"3M":    0,
+		"2":     0,
+		"M":     0,
+		"-2M":   0,
+	}
+	for size, expect := range tests {
+		if got := parseHugepageSize(size); got != expect {
+			t.Errorf("unexpected size of %s: %d, expected: %d", size, got, expect)
+		}
+	}
+}
+
+func TestLeakSize(t *testing.T) {
+	tests := []struct {
+		leak    leakSpec
+		elapsed []int
+		expect  []float64
+	}{
+		{leakSpec{growth: GrowthLinear, rate: 10}, []int{1, 2, 30}, []float64{10, 10, 10}},
+		{leakSpec{growth: GrowthExponential, rate: 10, factor: 2}, []int{1, 2, 4}, []float64{10, 20, 80}},
+		{leakSpec{growth: GrowthStep, rate: 10, interval: 3}, []int{1, 2, 3, 6}, []float64{0, 0, 30, 30}},
+	}
+	for _, tt := range tests {
+		for i, elapsed := range tt.elapsed {
+			if got := tt.leak.size(elapsed); got != tt.expect[i] {
+				t.Errorf("unexpected %s size at %ds: %f, expected: %f", tt.leak.growth, elapsed, got, tt.expect[i])
+			}
+		}
+	}
+}
+
+func TestLeakChunk(t *testing.T) {
+	const pageSize = 4096
+	tests := []struct {
+		leak   leakSpec
+		expect float64
+	}{
+		// the factor overflows the size to +Inf in seconds, it is capped every second
+		{leakSpec{growth: GrowthExponential, rate: 10, factor: 1000}, 10 + 3599*maxLeakChunk},
+		{leakSpec{growth: GrowthExponential, rate: 10, factor: 1000, maxSize: 5000}, 5000},
+		// every step is spread over the next seconds, the last one is just started
+		{leakSpec{growth: GrowthStep, rate: 1000, interval: 600}, 5*1000*600 + maxLeakChunk},
+		{leakSpec{growth: GrowthLinear, rate: 10}, 3600 * 10},
+	}
+	for _, tt := range tests {
+		var allocated, pending float64
+		for elapsed := 1; elapsed <= 3600; elapsed++ {
+			var size int
+			size, pending = tt.leak.chunk(elapsed, allocated, pending, pageSize)
+			if size < 0 || size > maxLeakChunk*1024*1024 || size%pageSize != 0 {
+				t.Fatalf("unexpected %s size at %ds: %d", tt.leak.growth, elapsed, size)
+			}
+			allocated += float64(size)
+		}
+		if allocated != tt.expect*1024*1024 {
+			t.Errorf("unexpected %s leak of factor %f in an hour: %.0fMB, expected: %.0fMB",
+				tt.leak.growth, tt.leak.factor, allocated/1024/1024, tt.expect)
+		}
+	}
+}
+
+func TestReadChunk(t *testing.T) {
+	chunk := make([]byte, 64)
+	for i := 0; i < len(chunk); i += 8 {
+		chunk[i] = byte(i / 8)
+	}
+	if got := readChunk(chunk); got != 28 {
+		t.Errorf("unexpected sum of the chunk: %d, expected: 28", got)
+	}
+}
+
+func TestThreadBufferSize(t *testing.T) {
+	for _, bufferSize := range []int64{1, 3, 64, 100, 255, 256, 1023} {
+		for _, threads := range []int{1, 3, 7, 20, 64, 96} {
+			size := threadBufferSize(bufferSize, threads)
+			if size < 2*bandwidthChunk || size%(2*bandwidthChunk) != 0 {
+				t.Errorf("unexpected buffer of %dMB and %d threads: %d, it must be an even number of chunks", bufferSize, threads, size)
+			}
+			// the offsets of the copy kernel, as stream wraps them
+			half := int(size / 2)
+			for offset := 0; offset < 3*int(size); offset += bandwidthChunk {
+				if wrapped := offset % half; half+wrapped+bandwidthChunk > int(size) {
+					t.Fatalf("the chunk at %d of %dMB and %d threads is out of the buffer %d", wrapped, bufferSize, threads, size)
+				}
+			}
+		}
+	}
+}
+
+func TestParseSwaps(t *testing.T) {
+	content := `Filename				Type		Size		Used		Priority
+/dev/sdb2                               partition	8388604		1024		-2
+/swap\040file                          file		1048572		0		10
+`
+	devices, err := parseSwaps(bufio.NewScanner(strings.NewReader(content)))
+	if err != nil {
+		t.Fatalf("parse swaps failed, %v", err)
+	}
+	expect := []swapDevice{
+		{path: "/dev/sdb2", kind: "partition", size: 8388604, used: 1024, priority: -2},
+		{path: "/swap file", kind: "file", size: 1048572, priority: 10},
+	}
+	if len(devices) != len(expect) {
+		t.Fatalf("unexpected devices: %v, expected: %v", devices, expect)
+	}
+	for i := range expect {
+		if devices[i] != expect[i] {
+			t.Errorf("unexpected device: %v, expected: %v", devices[i], expect[i])
+		}
+	}
+	if _, err := parseSwaps(bufio.NewScanner(strings.NewReader("Filename\n/dev/sdb2 partition 1"))); err == nil {
+		t.Errorf("expected error of the broken line")
+	}
+}
+
+func TestReadPressureTotal(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "memory.pressure")
+	content := "some avg10=20.00 avg60=5.00 avg300=1.00 total=123456\nfull avg10=10.00 avg60=2.00 avg300=0.50 total=654321\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s failed, %v", file, err)
+	}
+	for kind, expect := range map[string]int64{PSISome: 123456, PSIFull: 654321} {
+		total, err := readPressureTotal(file, kind)
+		if err != nil {
+			t.Fatalf("read %s total failed, %v", kind, err)
+		}
+		if total != expect {
+			t.Errorf("unexpected %s total: %d, expected: %d", kind, total, expect)
+		}
+	}
+	if _, err := readPressureTotal(file, "none"); err == nil {
+		t.Errorf("expected error of the missing kind")
+	}
+}
+
+func TestReleaseChunks(t *testing.T) {
+	chunks := make([][]byte, 4)
+	released := make([]weak.Pointer[byte], len(chunks))
+	for i := range chunks {
+		chunks[i] = make([]byte, 1024*1024)
+		released[i] = weak.Make(&chunks[i][0])
+	}
+	chunks = releaseChunks(chunks, 3)
+	if len(chunks) != 1 {
+		t.Fatalf("unexpected chunks after release: %d, expected: 1", len(chunks))
+	}
+	runtime.GC()
+	runtime.GC()
+	for i := 1; i < len(released); i++ {
+		if released[i].Value() != nil {
+			t.Errorf("released chunk %d is still reachable", i)
+		}
+	}
+	if released[0].Value() == nil {
+		t.Errorf("kept chunk is collected")
+	}
+	runtime.KeepAlive(chunks)
+}
+
+func TestParseResidentSpec(t *testing.T) {
+	resident, resp := parseResidentSpec(context.Background(), map[string]string{"touch-interval": "5"}, "ram", 50, 0, false)
+	if resp != nil {
+		t.Fatalf("parse resident spec failed, %s", resp.Err)
+	}
+	if resident.lock || resident.touchInterval != 5 {
+		t.Errorf("unexpected resident spec: %+v", resident)
+	}
+	for _, tt := range []struct {
+		flags map[string]string
+		mode  string
+	}{
+		{map[string]string{"touch-interval": "-1"}, "ram"},
+		{map[string]string{"touch-interval": "5"}, "cache"},
+		{map[string]string{"lock": "true"}, ModeTHP},
+	} {
+		if _, resp := parseResidentSpec(context.Background(), tt.flags, tt.mode, 50, 0, false); resp == nil {
+			t.Errorf("expected error of the flags %v of %s mode", tt.flags, tt.mode)
+		}
+	}
+}
+
+func TestGrowBlocks(t *testing.T) {
+	blockSize := int64(unsafe.Sizeof(Block{}))
+	var blocks []Block
+	for _, n := range []int{4, 1, 16} {
+		var err error
+		if blocks, err = growBlocks(blocks, n, true); err != nil {
+			t.Skipf("the blocks can not be locked, %v", err)
+		}
+		// only the blocks are locked, the moved ones are unlocked
+		locked := lockedBytes(t)
+		expected := int64(len(blocks)) * blockSize
+		if locked < expected || locked > expected+int64(os.Getpagesize())*2 {
+			t.Errorf("unexpected locked bytes after growing %d blocks: %d, expected: %d", n, locked, expected)
+		}
+	}
+	if err := unlockBlocks(blocks); err != nil {
+		t.Fatalf("unlock the blocks failed, %v", err)
+	}
+	if locked := lockedBytes(t); locked != 0 {
+		t.Errorf("unexpected locked bytes after unlocking: %d", locked)
+	}
+}
+
+// lockedBytes returns the VmLck of the test process
+func lockedBytes(t *testing.T) int64 {
+	content, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		t.Skipf("read the status of the process failed, %v", err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[0] == "VmLck:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				t.Fatalf("parse %s failed, %v", line, err)
+			}
+			return kb * 1024
+		}
+	}
+	t.Skip("VmLck is not found")
+	return 0
+}
+
+func TestEvictCaches(t *testing.T) {
+	empty := t.TempDir()
+	if err := evictCaches(context.Background(), defaultDropLevel, []string{empty}); err == nil {
+		t.Errorf("expected error of the path without files")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cached"), make([]byte, 4096), 0o644); err != nil {
+		t.Fatalf("write the file failed, %v", err)
+	}
+	if err := evictCaches(context.Background(), defaultDropLevel, []string{empty, dir}); err != nil {
+		t.Errorf("evict the caches failed, %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/log"
+	"github.com/chaosblade-io/chaosblade-spec-go/spec"
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+)
+
+// FileValue is the original content of a file changed by an experiment, for example a cgroup or sysfs file
+type FileValue struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// stateDir returns the directory of the state files, it is replaced by the tests
+var stateDir = util.GetProgramPath
+
+// stateFile returns the file which keeps the original values of the experiment, format: $programPath/name.uid
+func stateFile(name, uid string) string {
+	return path.Join(stateDir(), fmt.Sprintf("%s.%s", name, uid))
+}
+
+// StateExists returns true if the original values of the experiment have been saved
+func StateExists(name, uid string) bool {
+	return util.IsExist(stateFile(name, uid))
+}
+
+// SaveState saves the original values of the files before they are changed, so that another
+// process can recover them on destroy.
+func SaveState(name, uid string, values []FileValue) error {
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(stateFile(name, uid), bytes, 0o600)
+}
+
+// RestoreState writes the saved values back in the reverse order and removes the state file.
+// If uid is empty, the states of all experiments with the name are restored.
+func RestoreState(ctx context.Context, name, uid string) error {
+	return RestoreStateBy(ctx, name, uid, func(value FileValue) error {
+		return os.WriteFile(value.Path, []byte(value.Value), 0o644) //nolint:gosec
+	})
+}
+
+// RestoreStateBy is RestoreState with the restore function instead of writing the files, for the
+// states which are not file contents, for example the swap devices and their priorities.
+func RestoreStateBy(ctx context.Context, name, uid string, restore func(FileValue) error) error {
+	var files []string
+	if uid == "" || uid == spec.UnknownUid {
+		var err error
+		if files, err = filepath.Glob(stateFile(name, "*")); err != nil {
+			return err
+		}
+	} else if StateExists(name, uid) {
+		files = append(files, stateFile(name, uid))
+	}
+	if len(files) == 0 {
+		log.Warnf(ctx, "no state of %s found, uid: %s", name, uid)
+		return nil
+	}
+
+	var errs []string
+	for _, file := range files {
+		bytes, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		var values []FileValue
+		if err := json.Unmarshal(bytes, &values); err != nil {
+			errs = append(errs, fmt.Sprintf("%s is broken, %v", file, err))
+			continue
+		}
+		restored := true
+		for i := len(values) - 1; i >= 0; i-- {
+			if err := restore(values[i]); err != nil {
+				errs = append(errs, fmt.Sprintf("restore %s to %s failed, %v", values[i].Path, values[i].Value, err))
+				restored = false
+				continue
+			}
+			log.Infof(ctx, "restore %s to %s", values[i].Path, values[i].Value)
+		}
+		// keep the state file to retry if something can not be restored
+		if restored {
+			if err := os.Remove(file); err != nil {
+				log.Warnf(ctx, "remove state file %s failed, %v", file, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 The ChaosBlade Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaosblade-io/chaosblade-spec-go/util"
+)
+
+// useTempStateDir keeps the state files of the test in a temporary directory
+func useTempStateDir(t *testing.T) {
+	dir := t.TempDir()
+	stateDir = func() string { return dir }
+	t.Cleanup(func() { stateDir = util.GetProgramPath })
+}
+
+func TestSaveAndRestoreState(t *testing.T) {
+	useTempStateDir(t)
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	for _, file := range []string{first, second} {
+		if err := os.WriteFile(file, []byte("changed"), 0o644); err != nil {
+			t.Fatalf("write %s failed, %v", file, err)
+		}
+	}
+
+	values := []FileValue{{Path: first, Value: "1"}, {Path: second, Value: "2"}, {Path: first, Value: "0"}}
+	if err := SaveState("chaos_test", "state-uid", values); err != nil {
+		t.Fatalf("save state failed, %v", err)
+	}
+	if !StateExists("chaos_test", "state-uid") {
+		t.Fatalf("state is not saved")
+	}
+	if err := RestoreState(context.Background(), "chaos_test", "state-uid"); err != nil {
+		t.Fatalf("restore state failed, %v", err)
+	}
+	if StateExists("chaos_test", "state-uid") {
+		t.Errorf("state is not removed after restored")
+	}
+	// the values are restored in the reverse order, so the first saved value of a file wins
+	for file, expect := range map[string]string{first: "1", second: "2"} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s failed, %v", file, err)
+		}
+		if string(got) != expect {
+			t.Errorf("unexpected value of %s: %s, expected: %s", file, got, expect)
+		}
+	}
+}
+
+func TestRestoreStateByKeepsFailedState(t *testing.T) {
+	useTempStateDir(t)
+	values := []FileValue{{Path: "/dev/sdb2", Value: "-2"}}
+	if err := SaveState("chaos_test", "state-by-uid", values); err != nil {
+		t.Fatalf("save state failed, %v", err)
+	}
+	var restored []FileValue
+	failing := func(value FileValue) error {
+		restored = append(restored, value)
+		return errors.New("device busy")
+	}
+	if err := RestoreStateBy(context.Background(), "chaos_test", "state-by-uid", failing); err == nil {
+		t.Errorf("expected error of the failed restore")
+	}
+	if !StateExists("chaos_test", "state-by-uid") {
+		t.Errorf("state is removed after the restore failed")
+	}
+	if err := RestoreStateBy(context.Background(), "chaos_test", "state-by-uid", func(FileValue) error { return nil }); err != nil {
+		t.Fatalf("restore state failed, %v", err)
+	}
+	if StateExists("chaos_test", "state-by-uid") || len(restored) != 1 || restored[0] != values[0] {
+		t.Errorf("unexpected restored values: %v, expected: %v", restored, values)
+	}
+}